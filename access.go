@@ -0,0 +1,118 @@
+package meta
+
+import (
+	"go/types"
+	"sort"
+)
+
+// FieldAccess describes how a struct field is accessed from a package.
+type FieldAccess struct {
+	Field
+	// Expr is the selector expression for the field relative to a value of
+	// the struct type, ie ".Foo" or ".Inner.Foo".
+	// Embedded fields that are not accessible are omitted from Expr
+	// if the field is promoted through them.
+	Expr string
+	// Get is set if the field can be read from the package.
+	Get bool
+	// Set is set if the field can be assigned from the package.
+	// Nil embedded pointers along the path can be allocated.
+	Set bool
+}
+
+// AccessFields returns the fields of a struct type with their access from pkg
+// in index order.
+// If embed is set fields of embedded structs are included.
+func AccessFields(t types.Type, pkg *types.Package, embed bool) []FieldAccess {
+	s, ok := Struct(t)
+	if !ok {
+		return nil
+	}
+	var fields []FieldAccess
+	for _, ff := range NewFields(s, embed) {
+		if len(ff) == 0 {
+			continue
+		}
+		fields = append(fields, ff[0].Access(t, pkg))
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return comparePath(fields[i].Path, fields[j].Path) < 0
+	})
+	return fields
+}
+
+// Access reports how a field of struct type t can be accessed from pkg.
+func (f Field) Access(t types.Type, pkg *types.Package) FieldAccess {
+	a := FieldAccess{Field: f}
+	if len(f.Path) == 0 {
+		return a
+	}
+	expr := make([]byte, 0, len(f.Path)*16)
+	set := true
+	start := 0
+	last := len(f.Path) - 1
+	for i, p := range f.Path {
+		if i < last && !accessible(p.Var, pkg) {
+			// Try to select the next field through promotion
+			if _, ok := p.Type().(*types.Pointer); ok {
+				set = false
+			}
+			continue
+		}
+		obj, index, _ := types.LookupFieldOrMethod(t, true, pkg, p.Name())
+		if obj != p.Var || !samePath(index, f.Path[start:i+1]) {
+			return a
+		}
+		if ptr, ok := p.Type().(*types.Pointer); ok && i < last && !typeAccessible(ptr.Elem(), pkg) {
+			set = false
+		}
+		expr = append(expr, '.')
+		expr = append(expr, p.Name()...)
+		t = p.Type()
+		start = i + 1
+	}
+	a.Expr = string(expr)
+	a.Get = true
+	a.Set = set
+	return a
+}
+
+// accessible checks if an object can be referred to by name from pkg.
+func accessible(obj types.Object, pkg *types.Package) bool {
+	return obj.Exported() || obj.Pkg() == nil || obj.Pkg() == pkg
+}
+
+// typeAccessible checks if a type can be named from pkg.
+func typeAccessible(t types.Type, pkg *types.Package) bool {
+	switch t := t.(type) {
+	case *types.Named:
+		if !accessible(t.Obj(), pkg) {
+			return false
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if !typeAccessible(t.TypeArgs().At(i), pkg) {
+				return false
+			}
+		}
+		return true
+	case *types.Alias:
+		return accessible(t.Obj(), pkg)
+	case *types.Pointer:
+		return typeAccessible(t.Elem(), pkg)
+	default:
+		return true
+	}
+}
+
+// samePath checks if a field index sequence matches a path.
+func samePath(index []int, path FieldPath) bool {
+	if len(index) != len(path) {
+		return false
+	}
+	for i := range index {
+		if index[i] != path[i].Index {
+			return false
+		}
+	}
+	return true
+}
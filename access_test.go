@@ -0,0 +1,50 @@
+package meta_test
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestAccessFields(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/access", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("access", "example.com/access", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := types.NewPackage("example.com/other", "other")
+	for _, tc := range []struct {
+		Type   string
+		Pkg    *types.Package
+		Expect string
+	}{
+		{"T", other, ".Foo rw, - , .Exported.Baz rw, .Qux r, .Name rw, - "},
+		{"T", pkg.Types(), ".inner.Foo rw, .inner.bar rw, .Exported.Baz rw, .ptrInner.Qux rw, .Name rw, .secret rw"},
+		{"Ambiguous", other, "- , - "},
+		{"Ambiguous", pkg.Types(), ".inner.Foo rw, .inner.bar rw"},
+	} {
+		var access []string
+		for _, f := range meta.AccessFields(pkg.LookupType(tc.Type), tc.Pkg, true) {
+			mode := ""
+			if f.Get {
+				mode += "r"
+			}
+			if f.Set {
+				mode += "w"
+			}
+			if f.Expr == "" {
+				f.Expr = "-"
+			}
+			access = append(access, fmt.Sprintf("%s %s", f.Expr, mode))
+		}
+		if got := strings.Join(access, ", "); got != tc.Expect {
+			t.Errorf("Invalid access of %s from %s %q != %q", tc.Type, tc.Pkg.Path(), got, tc.Expect)
+		}
+	}
+}
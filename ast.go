@@ -70,6 +70,20 @@ func ForEachValueSpec(f *ast.File, fn func(spec *ast.ValueSpec)) {
 
 }
 
+func ForEachConstSpec(f *ast.File, fn func(spec *ast.ValueSpec)) {
+	if fn == nil {
+		return
+	}
+	for _, decl := range f.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.CONST {
+			for _, spec := range decl.Specs {
+				spec, _ := spec.(*ast.ValueSpec)
+				fn(spec)
+			}
+		}
+	}
+}
+
 func AppendStructs(specs []*ast.TypeSpec, f *ast.File) []*ast.TypeSpec {
 	for _, d := range f.Decls {
 		if d, ok := d.(*ast.GenDecl); ok && d.Tok == token.TYPE {
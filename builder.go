@@ -0,0 +1,435 @@
+package meta
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"io"
+	"strconv"
+)
+
+// Builder builds go/ast nodes for generated code.
+//
+// Type expressions are built from types.Type values using the builder's
+// qualifier and the packages they refer to are recorded as imports.
+type Builder struct {
+	qual    types.Qualifier
+	imports []*types.Package
+	pkg     *Package
+}
+
+// NewBuilder creates a builder that qualifies types with q.
+func NewBuilder(q types.Qualifier) *Builder {
+	return &Builder{qual: q}
+}
+
+// Builder creates a builder using the package qualifier.
+// Nodes are printed with the package file set so nodes from the package
+// syntax trees keep their layout and comments.
+func (p *Package) Builder() *Builder {
+	b := NewBuilder(p.Qualifier())
+	b.pkg = p
+	return b
+}
+
+// Imports returns the packages referred to by the types used in the builder
+// since the last call to Code.
+func (b *Builder) Imports() []*types.Package {
+	return b.imports
+}
+
+// Code prints nodes separated by blank lines and formats the result.
+// The returned code includes the imports recorded by the builder since the
+// last call to Code.
+func (b *Builder) Code(nodes ...ast.Node) (c Code) {
+	fprint := func(w io.Writer, node interface{}) error {
+		return printer.Fprint(w, token.NewFileSet(), node)
+	}
+	if b.pkg != nil {
+		fprint = b.pkg.Fprint
+	}
+	buf := bytes.Buffer{}
+	for i, node := range nodes {
+		if i > 0 {
+			buf.WriteString("\n\n")
+		}
+		if err := fprint(&buf, node); err != nil {
+			return c.Error(err)
+		}
+	}
+	buf.WriteByte('\n')
+	c.Code = buf.Bytes()
+	c.Imports = append(c.Imports, b.imports...)
+	b.imports = nil
+	return c.Format()
+}
+
+// Type returns the expression for a type and records its imports.
+func (b *Builder) Type(t types.Type) ast.Expr {
+	b.imports = TypeImports(b.imports, t)
+	return b.typeExpr(t)
+}
+
+func (b *Builder) typeName(obj *types.TypeName, args *types.TypeList) ast.Expr {
+	var x ast.Expr = ast.NewIdent(obj.Name())
+	if pkg := obj.Pkg(); pkg != nil && b.qual != nil {
+		if name := b.qual(pkg); name != "" {
+			x = &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent(obj.Name())}
+		}
+	}
+	if args.Len() > 0 {
+		indices := make([]ast.Expr, args.Len())
+		for i := range indices {
+			indices[i] = b.typeExpr(args.At(i))
+		}
+		x = &ast.IndexListExpr{X: x, Indices: indices}
+	}
+	return x
+}
+
+func (b *Builder) typeExpr(t types.Type) ast.Expr {
+	switch t := t.(type) {
+	case *types.Basic:
+		if t.Kind() == types.UnsafePointer {
+			name := "unsafe"
+			if b.qual != nil {
+				if q := b.qual(types.Unsafe); q != "" {
+					name = q
+				}
+			}
+			return &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent(t.Name())}
+		}
+		return ast.NewIdent(t.Name())
+	case *types.Named:
+		return b.typeName(t.Obj(), t.TypeArgs())
+	case *types.Alias:
+		return b.typeName(t.Obj(), t.TypeArgs())
+	case *types.TypeParam:
+		return ast.NewIdent(t.Obj().Name())
+	case *types.Pointer:
+		return &ast.StarExpr{X: b.typeExpr(t.Elem())}
+	case *types.Slice:
+		return &ast.ArrayType{Elt: b.typeExpr(t.Elem())}
+	case *types.Array:
+		return &ast.ArrayType{
+			Len: b.Int(int(t.Len())),
+			Elt: b.typeExpr(t.Elem()),
+		}
+	case *types.Map:
+		return &ast.MapType{
+			Key:   b.typeExpr(t.Key()),
+			Value: b.typeExpr(t.Elem()),
+		}
+	case *types.Chan:
+		dir := ast.SEND | ast.RECV
+		switch t.Dir() {
+		case types.SendOnly:
+			dir = ast.SEND
+		case types.RecvOnly:
+			dir = ast.RECV
+		}
+		return &ast.ChanType{Dir: dir, Value: b.typeExpr(t.Elem())}
+	case *types.Signature:
+		return b.funcType(nil, t.Params(), t.Results(), t.Variadic())
+	case *types.Struct:
+		fields := &ast.FieldList{}
+		for i := 0; i < t.NumFields(); i++ {
+			f := t.Field(i)
+			field := &ast.Field{Type: b.typeExpr(f.Type())}
+			if !f.Anonymous() {
+				field.Names = []*ast.Ident{ast.NewIdent(f.Name())}
+			}
+			if tag := t.Tag(i); tag != "" {
+				field.Tag = tagLit(tag)
+			}
+			fields.List = append(fields.List, field)
+		}
+		return &ast.StructType{Fields: fields}
+	case *types.Interface:
+		methods := &ast.FieldList{}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			methods.List = append(methods.List, &ast.Field{
+				Type: b.typeExpr(t.EmbeddedType(i)),
+			})
+		}
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			m := t.ExplicitMethod(i)
+			sig := m.Type().(*types.Signature)
+			methods.List = append(methods.List, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(m.Name())},
+				Type:  b.funcType(nil, sig.Params(), sig.Results(), sig.Variadic()),
+			})
+		}
+		return &ast.InterfaceType{Methods: methods}
+	case *types.Union:
+		var x ast.Expr
+		for i := 0; i < t.Len(); i++ {
+			term := t.Term(i)
+			y := b.typeExpr(term.Type())
+			if term.Tilde() {
+				y = &ast.UnaryExpr{Op: token.TILDE, X: y}
+			}
+			if x == nil {
+				x = y
+			} else {
+				x = &ast.BinaryExpr{X: x, Op: token.OR, Y: y}
+			}
+		}
+		return x
+	default:
+		return ast.NewIdent(t.String())
+	}
+}
+
+func (b *Builder) fieldList(vars *types.Tuple, variadic bool) *ast.FieldList {
+	list := &ast.FieldList{}
+	for i := 0; i < vars.Len(); i++ {
+		v := vars.At(i)
+		var typ ast.Expr
+		if s, ok := v.Type().(*types.Slice); ok && variadic && i == vars.Len()-1 {
+			typ = &ast.Ellipsis{Elt: b.typeExpr(s.Elem())}
+		} else {
+			typ = b.typeExpr(v.Type())
+		}
+		field := &ast.Field{Type: typ}
+		if v.Name() != "" {
+			field.Names = []*ast.Ident{ast.NewIdent(v.Name())}
+		}
+		list.List = append(list.List, field)
+	}
+	return list
+}
+
+func (b *Builder) funcType(tparams *types.TypeParamList, params, results *types.Tuple, variadic bool) *ast.FuncType {
+	fn := &ast.FuncType{
+		Params: b.fieldList(params, variadic),
+	}
+	if results.Len() > 0 {
+		fn.Results = b.fieldList(results, false)
+	}
+	if tparams.Len() > 0 {
+		fn.TypeParams = &ast.FieldList{}
+		for i := 0; i < tparams.Len(); i++ {
+			tp := tparams.At(i)
+			fn.TypeParams.List = append(fn.TypeParams.List, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(tp.Obj().Name())},
+				Type:  b.typeExpr(tp.Constraint()),
+			})
+		}
+	}
+	return fn
+}
+
+// Var creates a named variable to use as a parameter or receiver.
+func (b *Builder) Var(name string, t types.Type) *types.Var {
+	return types.NewParam(token.NoPos, nil, name, t)
+}
+
+// Func creates a function declaration from a signature.
+// If the signature has a receiver a method is declared.
+func (b *Builder) Func(name string, sig *types.Signature, body ...ast.Stmt) *ast.FuncDecl {
+	b.imports = TypeImports(b.imports, sig)
+	fn := &ast.FuncDecl{
+		Name: ast.NewIdent(name),
+		Type: b.funcType(sig.TypeParams(), sig.Params(), sig.Results(), sig.Variadic()),
+		Body: b.Block(body...),
+	}
+	if recv := sig.Recv(); recv != nil {
+		fn.Recv = b.fieldList(types.NewTuple(recv), false)
+	}
+	return fn
+}
+
+// Method creates a method declaration with a receiver.
+func (b *Builder) Method(recv *types.Var, name string, sig *types.Signature, body ...ast.Stmt) *ast.FuncDecl {
+	fn := b.Func(name, sig, body...)
+	fn.Recv = b.fieldList(types.NewTuple(recv), false)
+	b.imports = TypeImports(b.imports, recv)
+	return fn
+}
+
+// Field creates a struct field.
+// If name is empty the field is embedded.
+func (b *Builder) Field(name string, t types.Type, tag string) *ast.Field {
+	field := &ast.Field{Type: b.Type(t)}
+	if name != "" {
+		field.Names = []*ast.Ident{ast.NewIdent(name)}
+	}
+	if tag != "" {
+		field.Tag = tagLit(tag)
+	}
+	return field
+}
+
+// TypeDecl creates a type declaration.
+func (b *Builder) TypeDecl(name string, typ ast.Expr) *ast.GenDecl {
+	return &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{&ast.TypeSpec{
+			Name: ast.NewIdent(name),
+			Type: typ,
+		}},
+	}
+}
+
+// StructDecl creates a struct type declaration.
+func (b *Builder) StructDecl(name string, fields ...*ast.Field) *ast.GenDecl {
+	return b.TypeDecl(name, &ast.StructType{
+		Fields: &ast.FieldList{List: fields},
+	})
+}
+
+// Block creates a block statement.
+func (b *Builder) Block(stmts ...ast.Stmt) *ast.BlockStmt {
+	return &ast.BlockStmt{List: stmts}
+}
+
+// If creates an if statement.
+// The else branch is either nil, an *ast.IfStmt or an *ast.BlockStmt.
+func (b *Builder) If(init ast.Stmt, cond ast.Expr, body []ast.Stmt, els ast.Stmt) *ast.IfStmt {
+	return &ast.IfStmt{
+		Init: init,
+		Cond: cond,
+		Body: b.Block(body...),
+		Else: els,
+	}
+}
+
+// For creates a for statement.
+func (b *Builder) For(init ast.Stmt, cond ast.Expr, post ast.Stmt, body ...ast.Stmt) *ast.ForStmt {
+	return &ast.ForStmt{
+		Init: init,
+		Cond: cond,
+		Post: post,
+		Body: b.Block(body...),
+	}
+}
+
+// Range creates a for range statement defining key and value.
+// Empty key or value names are omitted.
+func (b *Builder) Range(key, value string, x ast.Expr, body ...ast.Stmt) *ast.RangeStmt {
+	stmt := &ast.RangeStmt{
+		X:    x,
+		Body: b.Block(body...),
+	}
+	if key != "" || value != "" {
+		stmt.Tok = token.DEFINE
+		if key == "" {
+			key = "_"
+		}
+		stmt.Key = ast.NewIdent(key)
+		if value != "" {
+			stmt.Value = ast.NewIdent(value)
+		}
+	}
+	return stmt
+}
+
+// Switch creates a switch statement.
+func (b *Builder) Switch(init ast.Stmt, tag ast.Expr, cases ...*ast.CaseClause) *ast.SwitchStmt {
+	body := &ast.BlockStmt{}
+	for _, c := range cases {
+		body.List = append(body.List, c)
+	}
+	return &ast.SwitchStmt{
+		Init: init,
+		Tag:  tag,
+		Body: body,
+	}
+}
+
+// Case creates a case clause, a nil list creates the default case.
+func (b *Builder) Case(list []ast.Expr, body ...ast.Stmt) *ast.CaseClause {
+	return &ast.CaseClause{
+		List: list,
+		Body: body,
+	}
+}
+
+// Return creates a return statement.
+func (b *Builder) Return(results ...ast.Expr) *ast.ReturnStmt {
+	return &ast.ReturnStmt{Results: results}
+}
+
+// Assign creates an assignment statement.
+func (b *Builder) Assign(lhs ast.Expr, tok token.Token, rhs ast.Expr) *ast.AssignStmt {
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{lhs},
+		Tok: tok,
+		Rhs: []ast.Expr{rhs},
+	}
+}
+
+// Define creates a short variable declaration.
+func (b *Builder) Define(name string, x ast.Expr) *ast.AssignStmt {
+	return b.Assign(ast.NewIdent(name), token.DEFINE, x)
+}
+
+// Expr creates an expression statement.
+func (b *Builder) Expr(x ast.Expr) *ast.ExprStmt {
+	return &ast.ExprStmt{X: x}
+}
+
+// Ident creates an identifier.
+func (b *Builder) Ident(name string) *ast.Ident {
+	return ast.NewIdent(name)
+}
+
+// Sel creates a selector expression x.a.b.c.
+func (b *Builder) Sel(x ast.Expr, names ...string) ast.Expr {
+	for _, name := range names {
+		x = &ast.SelectorExpr{X: x, Sel: ast.NewIdent(name)}
+	}
+	return x
+}
+
+// Call creates a call expression.
+func (b *Builder) Call(fn ast.Expr, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: fn, Args: args}
+}
+
+// Binary creates a binary expression.
+func (b *Builder) Binary(x ast.Expr, op token.Token, y ast.Expr) *ast.BinaryExpr {
+	return &ast.BinaryExpr{X: x, Op: op, Y: y}
+}
+
+// Unary creates a unary expression.
+func (b *Builder) Unary(op token.Token, x ast.Expr) ast.Expr {
+	if op == token.MUL {
+		return &ast.StarExpr{X: x}
+	}
+	return &ast.UnaryExpr{Op: op, X: x}
+}
+
+// Composite creates a composite literal of type t.
+func (b *Builder) Composite(t types.Type, elts ...ast.Expr) *ast.CompositeLit {
+	return &ast.CompositeLit{
+		Type: b.Type(t),
+		Elts: elts,
+	}
+}
+
+// KeyValue creates a key value expression for composite literals.
+func (b *Builder) KeyValue(key string, value ast.Expr) *ast.KeyValueExpr {
+	return &ast.KeyValueExpr{Key: ast.NewIdent(key), Value: value}
+}
+
+// String creates a string literal.
+func (b *Builder) String(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
+
+// Int creates an integer literal.
+func (b *Builder) Int(i int) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(i)}
+}
+
+func tagLit(tag string) *ast.BasicLit {
+	value := strconv.Quote(tag)
+	if strconv.CanBackquote(tag) {
+		value = "`" + tag + "`"
+	}
+	return &ast.BasicLit{Kind: token.STRING, Value: value}
+}
@@ -0,0 +1,111 @@
+package meta_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestBuilder(t *testing.T) {
+	target := types.NewPackage("example.com/foo", "foo")
+	timePkg := types.NewPackage("time", "time")
+	duration := types.NewNamed(types.NewTypeName(0, timePkg, "Duration", nil), types.Typ[types.Int64], nil)
+	timer := types.NewNamed(types.NewTypeName(0, target, "Timer", nil), types.NewStruct(nil, nil), nil)
+	imports := meta.NewImports(target)
+	b := meta.NewBuilder(imports.Qualifier())
+
+	decl := b.StructDecl("Timer",
+		b.Field("Timeout", duration, `json:"timeout"`),
+		b.Field("Names", types.NewSlice(types.Typ[types.String]), ""),
+	)
+	recv := b.Var("t", types.NewPointer(timer))
+	sig := types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(b.Var("d", duration)),
+		types.NewTuple(b.Var("", types.Typ[types.Bool])),
+		false,
+	)
+	method := b.Method(recv, "Expired", sig,
+		b.If(nil, b.Binary(b.Sel(b.Ident("t"), "Timeout"), token.EQL, b.Int(0)), []ast.Stmt{
+			b.Return(b.Ident("false")),
+		}, nil),
+		b.Range("_", "name", b.Sel(b.Ident("t"), "Names"),
+			b.Switch(nil, b.Ident("name"),
+				b.Case([]ast.Expr{b.String("")}, b.Return(b.Ident("true"))),
+			),
+		),
+		b.Return(b.Binary(b.Ident("d"), token.GTR, b.Sel(b.Ident("t"), "Timeout"))),
+	)
+	c := b.Code(decl, method)
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := "type Timer struct {\n" +
+		"\tTimeout time.Duration `json:\"timeout\"`\n" +
+		"\tNames   []string\n" +
+		"}\n" +
+		`
+func (t *Timer) Expired(d time.Duration) bool {
+	if t.Timeout == 0 {
+		return false
+	}
+	for _, name := range t.Names {
+		switch name {
+		case "":
+			return true
+		}
+	}
+	return d > t.Timeout
+}
+`
+	if c.String() != expect {
+		t.Errorf("Invalid code:\n%s", c)
+	}
+	if len(c.Imports) != 2 || imports.Len() != 1 {
+		t.Errorf("Invalid imports %v %s", c.Imports, imports)
+	}
+
+	c = b.Code(b.StructDecl("Ptr", b.Field("P", types.Typ[types.UnsafePointer], "")))
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if c.String() != "type Ptr struct {\n\tP unsafe.Pointer\n}\n" {
+		t.Errorf("Invalid code:\n%s", c)
+	}
+	if len(c.Imports) != 1 || c.Imports[0] != types.Unsafe {
+		t.Errorf("Invalid imports %v", c.Imports)
+	}
+}
+
+func TestPackageBuilder(t *testing.T) {
+	p := meta.NewParser(parser.ParseComments)
+	if err := p.ParseDir("testdata/body", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("body", "example.com/body", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decl ast.Decl
+	for _, f := range pkg.Files() {
+		for _, d := range f.Decls {
+			if fn, ok := d.(*ast.FuncDecl); ok && fn.Name.Name == "Reset" {
+				decl = fn
+			}
+		}
+	}
+	if decl == nil {
+		t.Fatal("Declaration not found")
+	}
+	c := pkg.Builder().Code(decl)
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := "// Reset sets the counter to zero.\nfunc (t *T) Reset() {\n\tprev := t.N\n\n\tt.N = 0\n\t_ = prev\n}\n"
+	if c.String() != expect {
+		t.Errorf("Invalid code:\n%s", c)
+	}
+}
@@ -10,6 +10,8 @@ type Code struct {
 	Imports []*types.Package
 	Code    []byte
 	err     error
+	regions []codeRegion
+	imports *Imports
 }
 
 func (c Code) Errorf(format string, a ...interface{}) Code {
@@ -67,27 +69,50 @@ func (c Code) Error(err error) Code {
 	return c
 }
 
+// Format formats the code with go/format.
+//
+// If formatting fails the unformatted code is kept and the error is
+// a *FormatError showing the source around each error.
 func (c Code) Format() Code {
-	c.Code, c.err = format.Source(c.Code)
+	src, err := format.Source(c.Code)
+	if err != nil {
+		c.err = newFormatError(c.Code, c.regions, err)
+		return c
+	}
+	c.Code, c.regions = src, nil
 	return c
 }
 
 func (c Code) Append(cc Code) Code {
+	start := len(c.Code)
 	c.Code = append(c.Code, cc.Code...)
-	c.Imports = append(c.Imports, cc.Imports...)
+	c = c.addImports(cc.Imports...)
 	if c.err == nil {
 		c.err = cc.err
 	}
+	if len(cc.regions) == 0 {
+		c.regions = c.record(c.regions, "Append", start)
+		return c
+	}
+	for _, r := range cc.regions {
+		r.start += start
+		r.end += start
+		c.regions = append(c.regions, r)
+	}
 	return c
 }
 
 func (c Code) Print(args ...interface{}) Code {
+	start := len(c.Code)
 	c.Code = append(c.Code, fmt.Sprint(args...)...)
+	c.regions = c.record(c.regions, "Print", start)
 	return c
 }
 
 func (c Code) Println(args ...interface{}) Code {
+	start := len(c.Code)
 	c.Code = append(c.Code, fmt.Sprintln(args...)...)
+	c.regions = c.record(c.regions, "Println", start)
 	return c
 }
 
@@ -101,11 +126,15 @@ func (c Code) QPrintf(q types.Qualifier, format string, args ...interface{}) Cod
 			args[i] = types.TypeString(t, q)
 		}
 	}
+	start := len(c.Code)
 	c.Code = append(c.Code, fmt.Sprintf(format, args...)...)
+	c.regions = c.record(c.regions, "QPrintf", start)
 	return c
 }
 func (c Code) Printf(format string, args ...interface{}) Code {
+	start := len(c.Code)
 	c.Code = append(c.Code, fmt.Sprintf(format, args...)...)
+	c.regions = c.record(c.regions, "Printf", start)
 	return c
 }
 
@@ -116,7 +145,7 @@ func (c Code) Import(args ...interface{}) Code {
 			if c.err = a.Err(); c.err != nil {
 				return c
 			}
-			c.Imports = append(c.Imports, a.Imports...)
+			c = c.addImports(a.Imports...)
 		case *Code:
 			if a == nil {
 				return c
@@ -124,14 +153,27 @@ func (c Code) Import(args ...interface{}) Code {
 			if c.err = a.Err(); c.err != nil {
 				return c
 			}
-			c.Imports = append(c.Imports, a.Imports...)
+			c = c.addImports(a.Imports...)
 		default:
+			n := len(c.Imports)
 			c.Imports = TypeImports(c.Imports, a)
+			if c.imports != nil {
+				c.imports.Add(c.Imports[n:]...)
+			}
 		}
 	}
 	return c
 }
 
+// addImports adds packages to the imports of the code and its import set.
+func (c Code) addImports(pkgs ...*types.Package) Code {
+	c.Imports = append(c.Imports, pkgs...)
+	if c.imports != nil {
+		c.imports.Add(pkgs...)
+	}
+	return c
+}
+
 func (c *Code) Write(p []byte) (int, error) {
 	c.Code = append(c.Code, p...)
 	return len(p), nil
@@ -0,0 +1,144 @@
+package meta
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+// Enum is a named type with constants declared in its package.
+type Enum struct {
+	*types.Named
+	Consts []*types.Const
+}
+
+// Values returns the distinct constant values of the enum in declaration order.
+// Constants with the same value as a previous constant are skipped.
+func (e Enum) Values() []*types.Const {
+	values := make([]*types.Const, 0, len(e.Consts))
+	for _, c := range e.Consts {
+		duplicate := false
+		for _, v := range values {
+			if constant.Compare(c.Val(), token.EQL, v.Val()) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			values = append(values, c)
+		}
+	}
+	return values
+}
+
+// Enums returns the constants of the package grouped by their named type.
+// Enums are ordered by the declaration of their first constant.
+func (p *Package) Enums() (enums []Enum) {
+	index := make(map[*types.Named]int)
+	for _, c := range p.DefinedConsts(nil) {
+		named, ok := c.Type().(*types.Named)
+		if !ok || named.Obj().Pkg() != p.pkg || c.Name() == "_" {
+			continue
+		}
+		i, ok := index[named]
+		if !ok {
+			i = len(enums)
+			index[named] = i
+			enums = append(enums, Enum{Named: named})
+		}
+		enums[i].Consts = append(enums[i].Consts, c)
+	}
+	return
+}
+
+var (
+	fmtPkg  = types.NewPackage("fmt", "fmt")
+	jsonPkg = types.NewPackage("encoding/json", "json")
+)
+
+// qualifiedName returns the qualified name of an object in pkg.
+func qualifiedName(q types.Qualifier, pkg *types.Package, name string) string {
+	if q == nil {
+		return pkg.Name() + "." + name
+	}
+	if p := q(pkg); p != "" {
+		return p + "." + name
+	}
+	return name
+}
+
+// EnumCode generates methods for an enum type.
+//
+// For an enum T it generates T.String, ParseT, TValues and the
+// encoding.TextMarshaler, encoding.TextUnmarshaler, json.Marshaler and
+// json.Unmarshaler methods. The code must be generated into the package
+// of T.
+func EnumCode(e Enum, q types.Qualifier) (c Code) {
+	b, ok := Basic(e.Named)
+	if !ok {
+		return c.Errorf("Enum %s is not a basic type", e.Named)
+	}
+	values := e.Values()
+	if len(values) == 0 {
+		return c.Errorf("Enum %s has no constants", e.Named)
+	}
+	name := e.Obj().Name()
+	typ := types.TypeString(e.Named, q)
+	c = c.Import(e.Named, fmtPkg, jsonPkg)
+	errorf := qualifiedName(q, fmtPkg, "Errorf")
+	sprintf := qualifiedName(q, fmtPkg, "Sprintf")
+	marshal := qualifiedName(q, jsonPkg, "Marshal")
+	unmarshal := qualifiedName(q, jsonPkg, "Unmarshal")
+	zero := ZeroValue(e.Named, q)
+	if zero.Err() != nil {
+		return zero
+	}
+
+	c = c.Printf("\n// String implements fmt.Stringer.\n")
+	c = c.Printf("func (x %s) String() string {\n\tswitch x {\n", typ)
+	for _, v := range values {
+		c = c.Printf("\tcase %s:\n\t\treturn %q\n", v.Name(), v.Name())
+	}
+	c = c.Printf("\tdefault:\n\t\treturn %s(\"%s(%%v)\", %s(x))\n\t}\n}\n", sprintf, name, b.Name())
+
+	c = c.Printf("\n// Parse%s parses a %s from its name.\n", name, name)
+	c = c.Printf("func Parse%s(s string) (%s, error) {\n\tswitch s {\n", name, typ)
+	for _, v := range values {
+		c = c.Printf("\tcase %q:\n\t\treturn %s, nil\n", v.Name(), v.Name())
+	}
+	c = c.Printf("\tdefault:\n\t\treturn %s, %s(\"Invalid %s %%q\", s)\n\t}\n}\n", zero, errorf, name)
+
+	c = c.Printf("\n// %sValues returns all values of %s.\n", name, name)
+	c = c.Printf("func %sValues() []%s {\n\treturn []%s{\n", name, typ, typ)
+	for _, v := range values {
+		c = c.Printf("\t\t%s,\n", v.Name())
+	}
+	c = c.Printf("\t}\n}\n")
+
+	c = c.Printf("\n// MarshalText implements encoding.TextMarshaler.\n")
+	c = c.Printf("func (x %s) MarshalText() ([]byte, error) {\n\tswitch x {\n\tcase ", typ)
+	for i, v := range values {
+		if i > 0 {
+			c = c.Printf(", ")
+		}
+		c = c.Printf("%s", v.Name())
+	}
+	c = c.Printf(":\n\t\treturn []byte(x.String()), nil\n")
+	c = c.Printf("\tdefault:\n\t\treturn nil, %s(\"Invalid %s %%v\", %s(x))\n\t}\n}\n", errorf, name, b.Name())
+
+	c = c.Printf("\n// UnmarshalText implements encoding.TextUnmarshaler.\n")
+	c = c.Printf("func (x *%s) UnmarshalText(data []byte) error {\n", typ)
+	c = c.Printf("\tv, err := Parse%s(string(data))\n\tif err != nil {\n\t\treturn err\n\t}\n", name)
+	c = c.Printf("\t*x = v\n\treturn nil\n}\n")
+
+	c = c.Printf("\n// MarshalJSON implements json.Marshaler.\n")
+	c = c.Printf("func (x %s) MarshalJSON() ([]byte, error) {\n", typ)
+	c = c.Printf("\tdata, err := x.MarshalText()\n\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	c = c.Printf("\treturn %s(string(data))\n}\n", marshal)
+
+	c = c.Printf("\n// UnmarshalJSON implements json.Unmarshaler.\n")
+	c = c.Printf("func (x *%s) UnmarshalJSON(data []byte) error {\n", typ)
+	c = c.Printf("\tvar s string\n\tif err := %s(data, &s); err != nil {\n\t\treturn err\n\t}\n", unmarshal)
+	c = c.Printf("\treturn x.UnmarshalText([]byte(s))\n}\n")
+	return c
+}
@@ -0,0 +1,92 @@
+package meta_test
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestEnums(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/enum", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("enum", "example.com/enum", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consts := pkg.DefinedConsts(nil); len(consts) != 7 {
+		t.Errorf("Invalid number of constants %d", len(consts))
+	}
+	enums := pkg.Enums()
+	if len(enums) != 2 {
+		t.Fatalf("Invalid enums %v", enums)
+	}
+	weekday := enums[0]
+	if weekday.Obj().Name() != "Weekday" || len(weekday.Consts) != 4 {
+		t.Errorf("Invalid enum %s %v", weekday.Named, weekday.Consts)
+	}
+	if values := weekday.Values(); len(values) != 3 || values[2].Val().String() != "2" {
+		t.Errorf("Invalid enum values %v", values)
+	}
+
+	f := pkg.NewFile()
+	for _, e := range enums {
+		f.Add(meta.EnumCode(e, f.Qualifier()))
+	}
+	c := f.Code()
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"\"encoding/json\"\n\t\"fmt\"\n",
+		"\tcase Tuesday:\n\t\treturn \"Tuesday\"\n",
+		"\t\treturn fmt.Sprintf(\"Weekday(%v)\", int(x))\n",
+		"\t\treturn \"\", fmt.Errorf(\"Invalid Color %q\", s)\n",
+		"func WeekdayValues() []Weekday {",
+		"func (x *Color) UnmarshalJSON(data []byte) error {",
+	} {
+		if !strings.Contains(c.String(), expect) {
+			t.Errorf("Enum code does not contain %q:\n%s", expect, c)
+		}
+	}
+	if strings.Contains(c.String(), "case FirstDay") {
+		t.Errorf("Duplicate enum value")
+	}
+
+	if _, err := p.ParseFile("testdata/enum/enum_gen.go", c.Code); err != nil {
+		t.Fatal(err)
+	}
+	checked, err := p.Package("enum", "example.com/enum", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stringer := meta.MakeInterface("String", nil, []types.Type{types.Typ[types.String]}, false)
+	if !types.Implements(checked.LookupType("Color"), stringer) {
+		t.Errorf("Enum does not implement fmt.Stringer")
+	}
+}
+
+func TestEnumRoundTrip(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/enum", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("enum", "example.com/enum", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := pkg.NewFile()
+	for _, e := range pkg.Enums() {
+		f.Add(meta.EnumCode(e, f.Qualifier()))
+	}
+	c := f.Code()
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := runRoundTrip(t, "testdata/enum", "example.com/enum", c.Code, "enum.go"); err != nil {
+		t.Errorf("Enum round trip failed: %s\n%s", err, out)
+	}
+}
@@ -1,8 +1,12 @@
 package meta
 
 import (
+	"fmt"
 	"go/types"
+	"reflect"
 	"sort"
+	"strings"
+	"unicode"
 )
 
 type FieldIndex struct {
@@ -33,6 +37,49 @@ func (p FieldPath) String() string {
 	return string(buf)
 }
 
+// Get returns the selector expression of the path on x.
+func (p FieldPath) Get(x string) (c Code) {
+	return c.Printf("%s%s", x, p)
+}
+
+// Guard returns a statement that executes body if none of the embedded
+// pointers along the path on x is nil and all conditions hold.
+func (p FieldPath) Guard(x string, body Code, conditions ...string) (c Code) {
+	var checks []string
+	for i := 0; i < len(p)-1; i++ {
+		if _, ok := p[i].Type().(*types.Pointer); ok {
+			checks = append(checks, x+p[:i+1].String()+" != nil")
+		}
+	}
+	checks = append(checks, conditions...)
+	if len(checks) == 0 {
+		return body
+	}
+	c = c.Printf("if %s {\n", strings.Join(checks, " && "))
+	c = c.Append(body)
+	return c.Printf("}\n")
+}
+
+// Alloc returns statements that allocate the nil embedded pointers
+// along the path on x.
+func (p FieldPath) Alloc(x string, q types.Qualifier) (c Code) {
+	for i := 0; i < len(p)-1; i++ {
+		if ptr, ok := p[i].Type().(*types.Pointer); ok {
+			sel := x + p[:i+1].String()
+			c = c.Import(ptr.Elem())
+			c = c.Printf("if %s == nil {\n\t%s = new(%s)\n}\n", sel, sel, types.TypeString(ptr.Elem(), q))
+		}
+	}
+	return c
+}
+
+// Set returns statements that assign value to the path on x
+// allocating nil embedded pointers along the path.
+func (p FieldPath) Set(x, value string, q types.Qualifier) Code {
+	c := p.Alloc(x, q)
+	return c.Printf("%s%s = %s\n", x, p, value)
+}
+
 // ShortestPath compares the paths of two fields.
 func ShortestPath(a, b FieldPath) int {
 	if len(a) < len(b) {
@@ -131,39 +178,260 @@ func (fields Fields) Add(field Field) Fields {
 
 // NewFields creates a field map for a struct.
 func NewFields(s *types.Struct, embed bool) Fields {
+	w := FieldWalker{Embed: embed}
+	return w.Fields(s)
+}
+
+// Merge adds the fields of a struct at path to the field map.
+// Embedded structs that form a cycle are added as fields.
+func (fields Fields) Merge(s *types.Struct, embed bool, path FieldPath) Fields {
+	w := FieldWalker{Embed: embed}
+	return w.Merge(fields, s, path)
+}
+
+// FieldWalker collects the fields of struct types.
+type FieldWalker struct {
+	// Embed merges the fields of embedded structs.
+	Embed bool
+	// MaxDepth limits the depth of merged embedded structs if it is positive.
+	// Embedded structs below MaxDepth are added as fields.
+	MaxDepth int
+
+	cycles []FieldCycle
+}
+
+// FieldCycle is an embedded field whose struct type embeds itself.
+type FieldCycle struct {
+	// Path is the path of the embedded field that closes the cycle.
+	Path FieldPath
+}
+
+func (c FieldCycle) String() string {
+	return fmt.Sprintf("%s embeds %s", c.Path, c.Path[len(c.Path)-1].Type())
+}
+
+// Cycles returns the embedding cycles found by the walker.
+func (w *FieldWalker) Cycles() []FieldCycle {
+	return w.cycles
+}
+
+// Fields creates a field map for a struct.
+func (w *FieldWalker) Fields(s *types.Struct) Fields {
 	if s == nil {
 		return nil
 	}
 	fields := Fields(make(map[string][]Field))
-	fields = fields.Merge(s, embed, nil)
-	return fields
-
+	return w.Merge(fields, s, nil)
 }
-func (fields Fields) Merge(s *types.Struct, embed bool, path FieldPath) Fields {
+
+// Merge adds the fields of a struct at path to the field map.
+func (w *FieldWalker) Merge(fields Fields, s *types.Struct, path FieldPath) Fields {
 	if fields == nil || s == nil {
 		return nil
 	}
+	structs := make([]*types.Struct, 0, len(path)+1)
+	for _, p := range path {
+		if st, ok := Deref(p.Type()).Underlying().(*types.Struct); ok {
+			structs = append(structs, st)
+		}
+	}
+	structs = append(structs, s)
+	return w.merge(fields, structs, path)
+}
 
+// merge adds the fields of the last struct in structs at path.
+// The other structs are the embedding structs along the path.
+func (w *FieldWalker) merge(fields Fields, structs []*types.Struct, path FieldPath) Fields {
+	s := structs[len(structs)-1]
 	depth := len(path)
-
 	for i := 0; i < s.NumFields(); i++ {
 		field := s.Field(i)
 		tag := s.Tag(i)
 		path = append(path[:depth], FieldIndex{i, field, tag})
-		if embed && field.Anonymous() {
-			t := field.Type().Underlying()
-			if ptr, isPointer := t.(*types.Pointer); isPointer {
-				t = ptr.Elem()
+		if w.Embed && field.Anonymous() && (w.MaxDepth <= 0 || depth < w.MaxDepth) {
+			if tt, ok := Deref(field.Type().Underlying()).Underlying().(*types.Struct); ok {
+				if !w.embeds(structs, tt) {
+					// embedded struct
+					fields = w.merge(fields, append(structs, tt), path)
+					continue
+				}
+				w.cycles = append(w.cycles, FieldCycle{path.Copy()})
 			}
-			if tt, ok := t.Underlying().(*types.Struct); ok {
-				// embedded struct
-				fields = fields.Merge(tt, embed, path)
+		}
+		fields = fields.Add(Field{field, tag, path.Copy()})
+	}
+	return fields
+}
+
+func (w *FieldWalker) embeds(structs []*types.Struct, s *types.Struct) bool {
+	for _, st := range structs {
+		if types.Identical(st, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// TagName returns the name of a field for a struct tag key.
+// If the tag has no valid name the Go name of the field is returned.
+func (f Field) TagName(key string) (name string, tagged bool) {
+	tag := reflect.StructTag(f.Tag).Get(key)
+	if i := strings.IndexByte(tag, ','); i != -1 {
+		tag = tag[:i]
+	}
+	if validTagName(tag) {
+		return tag, true
+	}
+	return FieldName(f.Var), false
+}
+
+// validTagName checks a tag name with the rules of encoding/json.
+func validTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+// comparePath compares two field paths by their indices.
+func comparePath(a, b FieldPath) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Index != b[i].Index {
+			return a[i].Index - b[i].Index
+		}
+	}
+	return len(a) - len(b)
+}
+
+// VisibleFields returns the fields of a struct that are visible to an encoder
+// using struct tags with key, following the rules of encoding/json.
+//
+// Fields of embedded structs are promoted unless the embedded field has a
+// tag name. Fields tagged with "-" are ignored. Among fields with the same
+// name the shallowest one wins, a tagged field wins over an untagged one at
+// the same depth and any other fields at the same depth cancel each other.
+// Fields are returned in index order.
+func VisibleFields(s *types.Struct, key string) []Field {
+	type visibleField struct {
+		Field
+		name   string
+		tagged bool
+	}
+	type embedded struct {
+		typ  types.Type
+		path FieldPath
+	}
+	var (
+		fields    []visibleField
+		visited   typeCount
+		next      = []embedded{{s, nil}}
+		nextCount = typeCount{{s, 1}}
+	)
+	for len(next) > 0 {
+		current, count := next, nextCount
+		next, nextCount = nil, nil
+		for _, e := range current {
+			if visited.get(e.typ) > 0 {
+				continue
+			}
+			visited.add(e.typ)
+			st, ok := e.typ.Underlying().(*types.Struct)
+			if !ok {
 				continue
 			}
+			for i := 0; i < st.NumFields(); i++ {
+				v, tag := st.Field(i), st.Tag(i)
+				if v.Anonymous() {
+					if !v.Exported() && !IsStruct(Deref(v.Type())) {
+						continue
+					}
+				} else if !v.Exported() {
+					continue
+				}
+				if reflect.StructTag(tag).Get(key) == "-" {
+					continue
+				}
+				path := append(e.path.Copy(), FieldIndex{i, v, tag})
+				f := visibleField{Field: Field{v, tag, path}}
+				f.name, f.tagged = f.TagName(key)
+				t := Deref(v.Type())
+				if f.tagged || !v.Anonymous() || !IsStruct(t) {
+					fields = append(fields, f)
+					if count.get(e.typ) > 1 {
+						// The same struct is embedded more than once at this depth.
+						fields = append(fields, f)
+					}
+					continue
+				}
+				if nextCount.add(t) == 1 {
+					next = append(next, embedded{t, path})
+				}
+			}
 		}
+	}
 
-		fields = fields.Add(Field{field, tag, path.Copy()})
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.Path) != len(b.Path) {
+			return len(a.Path) < len(b.Path)
+		}
+		if a.tagged != b.tagged {
+			return a.tagged
+		}
+		return comparePath(a.Path, b.Path) < 0
+	})
+	visible := make([]Field, 0, len(fields))
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		dominant := fields[i]
+		if j-i == 1 || len(fields[i+1].Path) > len(dominant.Path) || fields[i+1].tagged != dominant.tagged {
+			visible = append(visible, dominant.Field)
+		}
+		i = j
+	}
+	sort.Slice(visible, func(i, j int) bool {
+		return comparePath(visible[i].Path, visible[j].Path) < 0
+	})
+	return visible
+}
 
+// typeCount counts identical types.
+type typeCount []typeCounter
+
+type typeCounter struct {
+	typ types.Type
+	n   int
+}
+
+func (c typeCount) get(t types.Type) int {
+	for _, e := range c {
+		if types.Identical(e.typ, t) {
+			return e.n
+		}
 	}
-	return fields
+	return 0
+}
+
+func (c *typeCount) add(t types.Type) int {
+	for i := range *c {
+		if e := &(*c)[i]; types.Identical(e.typ, t) {
+			e.n++
+			return e.n
+		}
+	}
+	*c = append(*c, typeCounter{t, 1})
+	return 1
 }
@@ -0,0 +1,119 @@
+package meta_test
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestVisibleFields(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/fields", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("fields", "example.com/fields", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		Type, Key, Expect string
+	}{
+		{"Conflict", "json", ""},
+		{"Tagged", "json", "x Name"},
+		{"Shallow", "json", "x Name"},
+		{"Nested", "json", "Deep Name x"},
+		{"Self", "json", "ID"},
+		{"Yaml", "yaml", "a w"},
+		{"Yaml", "json", "Name x Z W"},
+	} {
+		s, ok := meta.Struct(pkg.LookupType(tc.Type))
+		if !ok {
+			t.Fatalf("Type %s not found", tc.Type)
+		}
+		var names []string
+		for _, f := range meta.VisibleFields(s, tc.Key) {
+			name, _ := f.TagName(tc.Key)
+			names = append(names, name)
+		}
+		if got := strings.Join(names, " "); got != tc.Expect {
+			t.Errorf("Invalid %s fields of %s %q != %q", tc.Key, tc.Type, got, tc.Expect)
+		}
+	}
+}
+
+func TestFieldWalker(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/fields", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("fields", "example.com/fields", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		Type     string
+		MaxDepth int
+		Fields   string
+		Cycles   string
+	}{
+		{"Self", 0, ".ID .Self", ".Self embeds *example.com/fields.Self"},
+		{"Loop", 0, ".Chain.C .Chain.Loop .L", ".Chain.Loop embeds *example.com/fields.Loop"},
+		{"Nested", 0, ".A.Name .A.X .Inner.Deep .Other", ""},
+		{"Nested", 1, ".A.Name .A.X .Inner.A .Inner.Deep .Other", ""},
+	} {
+		s, _ := meta.Struct(pkg.LookupType(tc.Type))
+		w := meta.FieldWalker{Embed: true, MaxDepth: tc.MaxDepth}
+		var paths, cycles []string
+		for _, ff := range w.Fields(s) {
+			paths = append(paths, ff[0].Path.String())
+		}
+		sort.Strings(paths)
+		for _, c := range w.Cycles() {
+			cycles = append(cycles, c.String())
+		}
+		if got := strings.Join(paths, " "); got != tc.Fields {
+			t.Errorf("Invalid fields of %s %q != %q", tc.Type, got, tc.Fields)
+		}
+		if got := strings.Join(cycles, ", "); got != tc.Cycles {
+			t.Errorf("Invalid cycles of %s %q != %q", tc.Type, got, tc.Cycles)
+		}
+	}
+}
+
+func TestFieldPathCode(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/fields", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("fields", "example.com/fields", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := meta.Struct(pkg.LookupType("Loop"))
+	path := meta.NewFields(s, true)["C"][0].Path
+	q := (*types.Package).Name
+	if get := path.Get("x").String(); get != "x.Chain.C" {
+		t.Errorf("Invalid getter %q", get)
+	}
+	guard := path.Guard("x", meta.Printf("use(x.Chain.C)\n"), "x.L > 0")
+	if expect := "if x.Chain != nil && x.L > 0 {\nuse(x.Chain.C)\n}\n"; guard.String() != expect {
+		t.Errorf("Invalid guard %q", guard)
+	}
+	set := path.Set("x", "42", q)
+	if expect := "if x.Chain == nil {\n\tx.Chain = new(fields.Chain)\n}\nx.Chain.C = 42\n"; set.String() != expect {
+		t.Errorf("Invalid setter %q", set)
+	}
+	if len(set.Imports) != 1 || set.Imports[0] != pkg.Types() {
+		t.Errorf("Invalid setter imports %v", set.Imports)
+	}
+	path = meta.NewFields(s, true)["L"][0].Path
+	if guard := path.Guard("x", meta.Printf("use(x.L)\n")); guard.String() != "use(x.L)\n" {
+		t.Errorf("Invalid guard %q", guard)
+	}
+	if set := path.Set("x", "1", q); set.String() != "x.L = 1\n" {
+		t.Errorf("Invalid setter %q", set)
+	}
+}
@@ -0,0 +1,76 @@
+package meta
+
+import (
+	"go/types"
+	"strings"
+)
+
+// File builds a complete Go source file from Code.
+type File struct {
+	// Package is the package name of the file.
+	Package string
+	// BuildTags is an optional build constraint expression for a //go:build line.
+	BuildTags string
+	// Generator is the name of the generator in the "Code generated" banner.
+	Generator string
+	// Imports is the import set of the file.
+	// Imports of the body Code are added to it when the file is rendered.
+	Imports *Imports
+	// Body is the code of the file after the import block.
+	Body []Code
+}
+
+// NewFile creates a file for code generated into the target package.
+func NewFile(name string, target *types.Package) *File {
+	return &File{
+		Package: name,
+		Imports: NewImports(target),
+	}
+}
+
+// Qualifier returns a qualifier using the file's import set.
+//
+// Code printed with it refers to packages by the alias they are imported
+// with in the file.
+func (f *File) Qualifier() types.Qualifier {
+	if f.Imports == nil {
+		f.Imports = NewImports(nil)
+	}
+	return f.Imports.Qualifier()
+}
+
+// Add appends code to the body of the file.
+func (f *File) Add(code ...Code) *File {
+	f.Body = append(f.Body, code...)
+	return f
+}
+
+// Code renders and formats the file source.
+func (f *File) Code() (c Code) {
+	imports := f.Imports
+	if imports == nil {
+		imports = NewImports(nil)
+	}
+	for i := range f.Body {
+		if err := f.Body[i].Err(); err != nil {
+			return c.Error(err)
+		}
+		imports.Add(f.Body[i].Imports...)
+	}
+	generator := f.Generator
+	if generator == "" {
+		generator = "go generate"
+	}
+	c = c.Printf("// Code generated by %s; DO NOT EDIT.\n\n", generator)
+	if tags := strings.TrimSpace(f.BuildTags); tags != "" {
+		c = c.Printf("//go:build %s\n\n", tags)
+	}
+	c = c.Printf("package %s\n\n", f.Package)
+	c = c.Append(imports.Code())
+	for i := range f.Body {
+		c = c.Println()
+		c = c.Append(Code{Code: f.Body[i].Code, regions: f.Body[i].regions})
+		c = c.Println()
+	}
+	return c.Format()
+}
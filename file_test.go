@@ -0,0 +1,73 @@
+package meta_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestFile(t *testing.T) {
+	target := types.NewPackage("example.com/foo", "foo")
+	named := types.NewNamed(types.NewTypeName(0, target, "Foo", nil), types.Typ[types.Int], nil)
+	duration := types.NewNamed(types.NewTypeName(0, types.NewPackage("time", "time"), "Duration", nil), types.Typ[types.Int64], nil)
+	q := func(pkg *types.Package) string {
+		if pkg == target {
+			return ""
+		}
+		return pkg.Name()
+	}
+	f := meta.NewFile("foo", target)
+	f.BuildTags = "linux"
+	f.Generator = "foogen"
+	f.Add(meta.Code{}.QPrintf(q, "var x %s = %s(0)", named, duration))
+	c := f.Code()
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := `// Code generated by foogen; DO NOT EDIT.
+
+//go:build linux
+
+package foo
+
+import (
+	"time"
+)
+
+var x Foo = time.Duration(0)
+`
+	if c.String() != expect {
+		t.Errorf("Invalid file:\n%s", c)
+	}
+}
+
+func TestFileQualifier(t *testing.T) {
+	target := types.NewPackage("example.com/foo", "foo")
+	mathRand := types.NewPackage("math/rand", "rand")
+	cryptoRand := types.NewPackage("crypto/rand", "rand")
+	a := types.NewNamed(types.NewTypeName(0, mathRand, "Rand", nil), types.NewStruct(nil, nil), nil)
+	b := types.NewNamed(types.NewTypeName(0, cryptoRand, "Reader", nil), types.NewStruct(nil, nil), nil)
+	f := meta.NewFile("foo", target)
+	q := f.Qualifier()
+	f.Add(meta.Code{}.QPrintf(q, "var a %s\nvar b %s\n", types.NewPointer(a), b))
+	c := f.Code()
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := `// Code generated by go generate; DO NOT EDIT.
+
+package foo
+
+import (
+	cryptorand "crypto/rand"
+	"math/rand"
+)
+
+var a *rand.Rand
+var b cryptorand.Reader
+`
+	if c.String() != expect {
+		t.Errorf("Invalid file:\n%s", c)
+	}
+}
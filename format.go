@@ -0,0 +1,143 @@
+package meta
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// formatContext is the number of source lines shown around a format error.
+const formatContext = 2
+
+// maxFormatErrors is the maximum number of errors shown by a FormatError.
+const maxFormatErrors = 10
+
+// TraceCode enables recording the call that writes each region of Code.
+//
+// Format errors then show the call that produced the invalid source.
+// Recording has a cost on every write so it is meant for debugging generators.
+var TraceCode bool
+
+// codeRegion is a region of code produced by a single call.
+type codeRegion struct {
+	start, end int
+	op         string
+	pc         []uintptr
+}
+
+// record appends the region of code written by op since start
+// if TraceCode is enabled.
+func (c Code) record(regions []codeRegion, op string, start int) []codeRegion {
+	if !TraceCode || start >= len(c.Code) {
+		return regions
+	}
+	pc := make([]uintptr, 16)
+	n := runtime.Callers(3, pc)
+	return append(regions, codeRegion{
+		start: start,
+		end:   len(c.Code),
+		op:    op,
+		pc:    pc[:n],
+	})
+}
+
+var pkgPrefix = reflect.TypeOf(Code{}).PkgPath() + "."
+
+// call returns the operation and the location of the first caller outside
+// this package.
+func (r codeRegion) call() string {
+	frames := runtime.CallersFrames(r.pc)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s at %s:%d", r.op, filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return r.op + " at unknown"
+		}
+	}
+}
+
+// FormatError is the error of a failed Code.Format.
+//
+// It keeps the unformatted source so the error message can show the lines
+// around each error and, if TraceCode is enabled, the call that produced them.
+type FormatError struct {
+	Source  []byte
+	Err     error
+	regions []codeRegion
+}
+
+func newFormatError(src []byte, regions []codeRegion, err error) *FormatError {
+	return &FormatError{
+		Source:  append([]byte(nil), src...),
+		Err:     err,
+		regions: regions,
+	}
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FormatError) Error() string {
+	var list scanner.ErrorList
+	if !errors.As(e.Err, &list) {
+		return e.Err.Error()
+	}
+	lines := bytes.Split(e.Source, []byte("\n"))
+	var sb strings.Builder
+	for i, err := range list {
+		if i == maxFormatErrors {
+			fmt.Fprintf(&sb, "(and %d more errors)\n", len(list)-i)
+			break
+		}
+		line := err.Pos.Line
+		fmt.Fprintf(&sb, "%d:%d: %s\n", line, err.Pos.Column, err.Msg)
+		if call := e.call(lines, line, err.Pos.Column); call != "" {
+			fmt.Fprintf(&sb, "\tproduced by %s\n", call)
+		}
+		for n := line - formatContext; n <= line+formatContext; n++ {
+			if n < 1 || n > len(lines) {
+				continue
+			}
+			marker := " "
+			if n == line {
+				marker = ">"
+			}
+			fmt.Fprintf(&sb, "%s %4d | %s\n", marker, n, lines[n-1])
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// call finds the call that produced the source at a line and column.
+func (e *FormatError) call(lines [][]byte, line, column int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	offset := 0
+	for _, l := range lines[:line-1] {
+		offset += len(l) + 1
+	}
+	end := offset + len(lines[line-1])
+	if pos := offset + column - 1; offset <= pos && pos < end {
+		for i := len(e.regions) - 1; i >= 0; i-- {
+			if r := e.regions[i]; r.start <= pos && pos < r.end {
+				return r.call()
+			}
+		}
+	}
+	// fallback to the last region that writes on the line
+	for i := len(e.regions) - 1; i >= 0; i-- {
+		if r := e.regions[i]; r.start <= end && offset < r.end {
+			return r.call()
+		}
+	}
+	return ""
+}
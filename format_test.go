@@ -0,0 +1,61 @@
+package meta_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestFormatError(t *testing.T) {
+	meta.TraceCode = true
+	defer func() { meta.TraceCode = false }()
+	c := meta.Printf("func foo() {\n")
+	c = c.Printf("\tx := %s\n", "1 +")
+	c = c.Append(meta.Printf("}\n"))
+	src := c.String()
+	c = c.Format()
+	err := c.Err()
+	if err == nil {
+		t.Fatal("Expected format error")
+	}
+	if c.String() != src {
+		t.Errorf("Unformatted source not kept")
+	}
+	var ferr *meta.FormatError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("Invalid error type %T", err)
+	}
+	if string(ferr.Source) != src {
+		t.Errorf("Invalid error source %q", ferr.Source)
+	}
+	msg := err.Error()
+	for _, expect := range []string{
+		"3:1: expected operand",
+		"produced by Printf at format_test.go:16",
+		">    3 | }",
+		"     2 | \tx := 1 +",
+	} {
+		if !strings.Contains(msg, expect) {
+			t.Errorf("Error message does not contain %q:\n%s", expect, msg)
+		}
+	}
+}
+
+func TestFormatErrorNoTrace(t *testing.T) {
+	c := meta.Printf("func foo() {\n")
+	c = c.Printf("\tx := %s\n", "1 +")
+	c = c.Append(meta.Printf("}\n"))
+	err := c.Format().Err()
+	if err == nil {
+		t.Fatal("Expected format error")
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "produced by") {
+		t.Errorf("Error message contains untraced call:\n%s", msg)
+	}
+	if !strings.Contains(msg, ">    3 | }") {
+		t.Errorf("Error message does not show the source:\n%s", msg)
+	}
+}
@@ -0,0 +1,189 @@
+package meta
+
+import (
+	"go/types"
+)
+
+// TypeParams returns the type parameters of a generic type or function signature.
+func TypeParams(t types.Type) *types.TypeParamList {
+	switch t := t.(type) {
+	case *types.Named:
+		return t.TypeParams()
+	case *types.Alias:
+		return t.TypeParams()
+	case *types.Signature:
+		return t.TypeParams()
+	default:
+		return nil
+	}
+}
+
+// TypeArgs returns the type arguments of an instantiated type.
+func TypeArgs(t types.Type) *types.TypeList {
+	switch t := t.(type) {
+	case *types.Named:
+		return t.TypeArgs()
+	case *types.Alias:
+		return t.TypeArgs()
+	default:
+		return nil
+	}
+}
+
+// Generic checks if t is a generic type that has not been instantiated.
+func Generic(t types.Type) bool {
+	return TypeParams(t).Len() > 0 && TypeArgs(t).Len() == 0
+}
+
+// Instantiate instantiates a generic type with concrete type arguments.
+// The type arguments are verified to satisfy the type parameter constraints.
+func Instantiate(t types.Type, args ...types.Type) (types.Type, error) {
+	return types.Instantiate(nil, t, args, true)
+}
+
+// TypeParam returns the type parameter t if it is one.
+func TypeParam(t types.Type) (*types.TypeParam, bool) {
+	p, ok := t.(*types.TypeParam)
+	return p, ok
+}
+
+// Constraint returns the constraint interface of a type parameter.
+func Constraint(t types.Type) (*types.Interface, bool) {
+	if p, ok := TypeParam(t); ok {
+		iface, ok := p.Constraint().Underlying().(*types.Interface)
+		return iface, ok
+	}
+	return nil, false
+}
+
+// TypeTerms returns the type terms of a type parameter or constraint interface.
+//
+// The terms of embedded interfaces are intersected so the result describes the
+// type set of the constraint. A nil result means the type set is not
+// restricted by any terms.
+func TypeTerms(t types.Type) []*types.Term {
+	if iface, ok := Constraint(t); ok {
+		return interfaceTerms(iface)
+	}
+	if t == nil {
+		return nil
+	}
+	switch t := t.Underlying().(type) {
+	case *types.Interface:
+		return interfaceTerms(t)
+	case *types.Union:
+		return unionTerms(t)
+	default:
+		return nil
+	}
+}
+
+func interfaceTerms(iface *types.Interface) (terms []*types.Term) {
+	if iface.IsMethodSet() {
+		return nil
+	}
+	restricted := false
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		var embedded []*types.Term
+		switch t := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			embedded = unionTerms(t)
+		default:
+			if e, ok := t.Underlying().(*types.Interface); ok {
+				embedded = interfaceTerms(e)
+				if embedded == nil {
+					continue
+				}
+			} else {
+				embedded = []*types.Term{types.NewTerm(false, t)}
+			}
+		}
+		if restricted {
+			terms = intersectTerms(terms, embedded)
+		} else {
+			terms, restricted = embedded, true
+		}
+	}
+	if restricted && terms == nil {
+		// empty type set
+		terms = []*types.Term{}
+	}
+	return terms
+}
+
+func unionTerms(u *types.Union) (terms []*types.Term) {
+	terms = make([]*types.Term, 0, u.Len())
+	for i := 0; i < u.Len(); i++ {
+		term := u.Term(i)
+		if iface, ok := term.Type().Underlying().(*types.Interface); ok {
+			terms = append(terms, interfaceTerms(iface)...)
+			continue
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+func intersectTerms(a, b []*types.Term) (terms []*types.Term) {
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case x.Tilde() && y.Tilde():
+				if types.Identical(x.Type(), y.Type()) {
+					terms = append(terms, x)
+				}
+			case x.Tilde():
+				if types.Identical(x.Type(), y.Type().Underlying()) {
+					terms = append(terms, y)
+				}
+			case y.Tilde():
+				if types.Identical(x.Type().Underlying(), y.Type()) {
+					terms = append(terms, x)
+				}
+			default:
+				if types.Identical(x.Type(), y.Type()) {
+					terms = append(terms, x)
+				}
+			}
+		}
+	}
+	return terms
+}
+
+// CoreType returns the core type of t.
+//
+// For type parameters this is the single underlying type of all types in
+// its type set, if one exists. For other types it is the underlying type.
+func CoreType(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	if _, ok := TypeParam(t); !ok {
+		return t.Underlying()
+	}
+	terms := TypeTerms(t)
+	if len(terms) == 0 {
+		return nil
+	}
+	core := terms[0].Type().Underlying()
+	for _, term := range terms[1:] {
+		if !types.Identical(core, term.Type().Underlying()) {
+			return nil
+		}
+	}
+	return core
+}
+
+// allTerms checks if all types in the type set of a type parameter match.
+func allTerms(t *types.TypeParam, match func(t types.Type) bool) bool {
+	terms := TypeTerms(t)
+	if len(terms) == 0 {
+		return false
+	}
+	for _, term := range terms {
+		if !match(term.Type()) {
+			return false
+		}
+	}
+	return true
+}
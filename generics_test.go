@@ -0,0 +1,122 @@
+package meta_test
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestGenerics(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/generic", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("generic", "example.com/generic", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := pkg.LookupType("List")
+	if !meta.Generic(list) {
+		t.Errorf("List is not generic")
+	}
+	terms := meta.TypeTerms(list.TypeParams().At(0))
+	if len(terms) != 3 {
+		t.Errorf("Invalid number of terms %d", len(terms))
+	}
+	integer := pkg.LookupType("Integer")
+	if terms := meta.TypeTerms(integer); len(terms) != 2 {
+		t.Errorf("Invalid number of intersected terms %d", len(terms))
+	}
+
+	if _, err := pkg.Instantiate("List", types.Typ[types.String]); err == nil {
+		t.Errorf("Instantiated List with invalid type argument")
+	}
+	typ, err := pkg.Instantiate("List", types.Typ[types.Int])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Generic(typ) {
+		t.Errorf("Instantiated List is generic")
+	}
+	if s, ok := meta.Struct(typ); !ok || s.Field(0).Type().String() != "[]int" {
+		t.Errorf("Invalid instantiated struct %s", typ)
+	}
+
+	timePkg := pkg.FindImport("time")
+	timeType := timePkg.Scope().Lookup("Time").Type()
+	pair, err := pkg.Instantiate("Pair", types.Typ[types.String], timeType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imports := meta.TypeImports(nil, pair)
+	if len(imports) != 2 || imports[1] != timePkg {
+		t.Errorf("Invalid imports %v", imports)
+	}
+}
+
+func TestCoreType(t *testing.T) {
+	slice := types.NewSlice(types.Typ[types.Int])
+	constraint := types.NewInterfaceType(nil, []types.Type{
+		types.NewUnion([]*types.Term{types.NewTerm(true, slice)}),
+	})
+	T := types.NewTypeParam(types.NewTypeName(0, nil, "T", nil), constraint)
+	if _, ok := meta.Slice(T); !ok {
+		t.Errorf("Core type of %s is not a slice", T)
+	}
+	if meta.Nilable(T) {
+		t.Errorf("Type parameter %s is nilable", T)
+	}
+	mapConstraint := types.NewInterfaceType(nil, []types.Type{
+		types.NewUnion([]*types.Term{types.NewTerm(true, types.NewMap(types.Typ[types.String], types.Typ[types.Int]))}),
+	})
+	M := types.NewTypeParam(types.NewTypeName(0, nil, "M", nil), mapConstraint)
+	if !meta.Nilable(M) {
+		t.Errorf("Type parameter %s is not nilable", M)
+	}
+	if !meta.Sized(T) {
+		t.Errorf("Type parameter %s is not sized", T)
+	}
+}
+
+func TestTypeImportsTypeParams(t *testing.T) {
+	fmtPkg := types.NewPackage("fmt", "fmt")
+	stringer := types.NewNamed(types.NewTypeName(0, fmtPkg, "Stringer", nil), types.NewInterfaceType(nil, nil), nil)
+	T := types.NewTypeParam(types.NewTypeName(0, nil, "T", nil), stringer)
+	sig := types.NewSignatureType(nil, nil, []*types.TypeParam{T},
+		types.NewTuple(types.NewParam(0, nil, "x", T)), nil, false)
+	if imports := meta.TypeImports(nil, sig); len(imports) != 1 || imports[0] != fmtPkg {
+		t.Errorf("Invalid signature imports %v", imports)
+	}
+
+	// type parameter with a constraint referring to itself
+	timePkg := types.NewPackage("time", "time")
+	duration := types.NewNamed(types.NewTypeName(0, timePkg, "Duration", nil), types.Typ[types.Int64], nil)
+	S := types.NewTypeParam(types.NewTypeName(0, nil, "S", nil), nil)
+	S.SetConstraint(types.NewInterfaceType([]*types.Func{
+		types.NewFunc(0, nil, "Next", types.NewSignatureType(nil, nil, nil,
+			types.NewTuple(types.NewParam(0, nil, "d", duration)),
+			types.NewTuple(types.NewParam(0, nil, "", S)), false)),
+	}, nil).Complete())
+	if imports := meta.TypeImports(nil, types.NewSlice(S)); len(imports) != 0 {
+		t.Errorf("Invalid type parameter imports %v", imports)
+	}
+	generic := types.NewSignatureType(nil, nil, []*types.TypeParam{S},
+		types.NewTuple(types.NewParam(0, nil, "s", S)), nil, false)
+	if imports := meta.TypeImports(nil, generic); len(imports) != 1 || imports[0] != timePkg {
+		t.Errorf("Invalid type parameter list imports %v", imports)
+	}
+
+	b := meta.NewBuilder((*types.Package).Name)
+	c := b.Code(b.Func("F", sig))
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(c.String(), "func F[T fmt.Stringer](x T)") {
+		t.Errorf("Invalid generic function %s", c)
+	}
+	if len(c.Imports) != 1 || c.Imports[0] != fmtPkg {
+		t.Errorf("Invalid generic function imports %v", c.Imports)
+	}
+}
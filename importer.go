@@ -0,0 +1,116 @@
+package meta
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+)
+
+// Importer type-checks imported packages from source.
+//
+// Import paths are resolved with go/build so module dependencies, the module
+// cache and vendor directories are found the same way `go build` finds them.
+type Importer struct {
+	fset     *token.FileSet
+	ctxt     *build.Context
+	packages map[string]*types.Package
+}
+
+var _ types.ImporterFrom = (*Importer)(nil)
+
+// NewImporter creates a source importer using a build context.
+// If ctxt is nil build.Default is used.
+func NewImporter(fset *token.FileSet, ctxt *build.Context) *Importer {
+	if fset == nil {
+		fset = token.NewFileSet()
+	}
+	if ctxt == nil {
+		ctxt = &build.Default
+	}
+	return &Importer{
+		fset:     fset,
+		ctxt:     ctxt,
+		packages: make(map[string]*types.Package),
+	}
+}
+
+// Import implements types.Importer resolving path relative to the working directory.
+func (imp *Importer) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, ".", 0)
+}
+
+// importing marks a package that is being type-checked to detect import cycles.
+var importing types.Package
+
+// ImportFrom implements types.ImporterFrom.
+func (imp *Importer) ImportFrom(path, dir string, _ types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	bp, err := imp.resolve(path, dir)
+	if err != nil {
+		return nil, err
+	}
+	return imp.check(bp, imp)
+}
+
+// resolve finds the package for an import path in dir.
+func (imp *Importer) resolve(path, dir string) (*build.Package, error) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	ctxt := *imp.ctxt
+	if ctxt.Dir == "" {
+		// go/build runs `go list` in ctxt.Dir to resolve module imports
+		ctxt.Dir = dir
+	}
+	return ctxt.Import(path, dir, 0)
+}
+
+// check type-checks a package from source resolving its imports with from.
+func (imp *Importer) check(bp *build.Package, from types.Importer) (*types.Package, error) {
+	if pkg, ok := imp.packages[bp.Dir]; ok {
+		if pkg == &importing {
+			return nil, fmt.Errorf("Import cycle through package %q", bp.ImportPath)
+		}
+		return pkg, nil
+	}
+	imp.packages[bp.Dir] = &importing
+	defer func() {
+		if imp.packages[bp.Dir] == &importing {
+			delete(imp.packages, bp.Dir)
+		}
+	}()
+
+	filenames := make([]string, 0, len(bp.GoFiles)+len(bp.CgoFiles))
+	filenames = append(filenames, bp.GoFiles...)
+	filenames = append(filenames, bp.CgoFiles...)
+	files := make([]*ast.File, 0, len(filenames))
+	for _, name := range filenames {
+		f, err := parser.ParseFile(imp.fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	config := types.Config{
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Importer:         from,
+	}
+	pkg, err := config.Check(bp.ImportPath, imp.fset, files, nil)
+	if err != nil {
+		return nil, err
+	}
+	imp.packages[bp.Dir] = pkg
+	return pkg, nil
+}
+
+// register adds a package checked from the files in dir to the cache.
+func (imp *Importer) register(dir string, pkg *types.Package) {
+	imp.packages[dir] = pkg
+}
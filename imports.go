@@ -0,0 +1,237 @@
+package meta
+
+import (
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Imports is the set of imports of a generated file.
+//
+// Imports are deduplicated by path and packages with the same name are
+// assigned aliases in the order they were added.
+// The target package the code is generated into is never imported.
+type Imports struct {
+	target string
+	paths  map[string]string
+	names  map[string]string
+}
+
+// NewImports creates an import set for code generated into target package.
+func NewImports(target *types.Package) *Imports {
+	imports := Imports{
+		paths: make(map[string]string),
+		names: make(map[string]string),
+	}
+	if target != nil {
+		imports.target = target.Path()
+	}
+	return &imports
+}
+
+// Target returns the path of the package the code is generated into.
+func (imports *Imports) Target() string {
+	return imports.target
+}
+
+// Add adds packages to the import set.
+func (imports *Imports) Add(pkgs ...*types.Package) *Imports {
+	for _, pkg := range pkgs {
+		if pkg != nil {
+			imports.Import(pkg.Path(), pkg.Name())
+		}
+	}
+	return imports
+}
+
+// Import adds an import path with a package name and returns the name
+// to use when referring to the package.
+// If name is empty the last element of the path is used.
+// It returns an empty string for the target package.
+func (imports *Imports) Import(pkgPath, name string) string {
+	if pkgPath == imports.target {
+		return ""
+	}
+	if alias, ok := imports.paths[pkgPath]; ok {
+		return alias
+	}
+	if name == "" {
+		name = packageName(pkgPath)
+	}
+	alias := name
+	if _, taken := imports.names[alias]; taken {
+		alias = pathAlias(pkgPath)
+	}
+	for i := 2; ; i++ {
+		if _, taken := imports.names[alias]; !taken {
+			break
+		}
+		alias = name + strconv.Itoa(i)
+	}
+	imports.paths[pkgPath] = alias
+	imports.names[alias] = pkgPath
+	return alias
+}
+
+// Name returns the name used for a package or an empty string if it is the
+// target package or it has not been imported.
+func (imports *Imports) Name(pkg *types.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	return imports.paths[pkg.Path()]
+}
+
+// Qualifier returns a qualifier that adds packages to the import set and
+// qualifies them by the name the import set assigned to them.
+func (imports *Imports) Qualifier() types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg == nil {
+			return ""
+		}
+		return imports.Import(pkg.Path(), pkg.Name())
+	}
+}
+
+// Len returns the number of imports.
+func (imports *Imports) Len() int {
+	return len(imports.paths)
+}
+
+// Paths returns the sorted import paths.
+func (imports *Imports) Paths() []string {
+	paths := make([]string, 0, len(imports.paths))
+	for p := range imports.paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Code renders an import block with standard library imports grouped first.
+func (imports *Imports) Code() (c Code) {
+	if imports.Len() == 0 {
+		return
+	}
+	var std, other []string
+	for _, p := range imports.Paths() {
+		if isStdPath(p) {
+			std = append(std, p)
+		} else {
+			other = append(other, p)
+		}
+	}
+	c = c.Println("import (")
+	for i, group := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 && len(group) > 0 {
+			c = c.Println()
+		}
+		for _, p := range group {
+			if alias := imports.paths[p]; alias != packageName(p) {
+				c = c.Printf("\t%s %q\n", alias, p)
+			} else {
+				c = c.Printf("\t%q\n", p)
+			}
+		}
+	}
+	return c.Println(")")
+}
+
+func (imports *Imports) String() string {
+	return imports.Code().String()
+}
+
+// WithImports attaches an import set to the code.
+//
+// The imports of the code and all imports added later by Import, QPrintf
+// and Append are added to the set.
+func (c Code) WithImports(imports *Imports) Code {
+	c.imports = imports
+	if imports != nil {
+		imports.Add(c.Imports...)
+	}
+	return c
+}
+
+// ImportSet returns the import set attached to the code.
+// If no import set is attached a new one is created for target with the
+// imports of the code.
+func (c Code) ImportSet(target *types.Package) *Imports {
+	if c.imports != nil {
+		return c.imports
+	}
+	return NewImports(target).Add(c.Imports...)
+}
+
+// Qualifier returns a qualifier using the import set attached to the code.
+// If no import set is attached packages are qualified by their name.
+func (c Code) Qualifier() types.Qualifier {
+	if c.imports != nil {
+		return c.imports.Qualifier()
+	}
+	return (*types.Package).Name
+}
+
+func isStdPath(p string) bool {
+	if i := strings.IndexByte(p, '/'); i != -1 {
+		p = p[:i]
+	}
+	return !strings.Contains(p, ".")
+}
+
+// packageName guesses the package name from an import path.
+func packageName(pkgPath string) string {
+	name := path.Base(pkgPath)
+	if isVersion(name) {
+		// module major version suffix
+		name = path.Base(path.Dir(pkgPath))
+	}
+	if i := strings.IndexByte(name, '.'); i != -1 {
+		// gopkg.in/yaml.v2
+		name = name[:i]
+	}
+	return identifier(strings.TrimPrefix(name, "go-"))
+}
+
+func isVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// pathAlias creates an alias for a package from the last two elements of its path.
+func pathAlias(pkgPath string) string {
+	dir, name := path.Split(pkgPath)
+	if isVersion(name) {
+		dir, name = path.Split(path.Clean(dir))
+	}
+	parent := path.Base(path.Clean(dir))
+	if parent == "." || parent == "/" {
+		return identifier(name)
+	}
+	return identifier(parent + name)
+}
+
+func identifier(s string) string {
+	id := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case 'a' <= c && c <= 'z', c == '_':
+			id = append(id, c)
+		case 'A' <= c && c <= 'Z':
+			id = append(id, c-'A'+'a')
+		case '0' <= c && c <= '9':
+			if len(id) > 0 {
+				id = append(id, c)
+			}
+		}
+	}
+	if len(id) == 0 {
+		return "pkg"
+	}
+	return string(id)
+}
@@ -0,0 +1,53 @@
+package meta_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestImports(t *testing.T) {
+	target := types.NewPackage("example.com/foo", "foo")
+	imports := meta.NewImports(target)
+	imports.Add(
+		types.NewPackage("math/rand", "rand"),
+		types.NewPackage("fmt", "fmt"),
+		target,
+		types.NewPackage("crypto/rand", "rand"),
+		types.NewPackage("math/rand", "rand"),
+		types.NewPackage("gopkg.in/yaml.v2", "yaml"),
+	)
+	if imports.Len() != 4 {
+		t.Errorf("Invalid number of imports %d", imports.Len())
+	}
+	if name := imports.Import("crypto/rand", ""); name != "cryptorand" {
+		t.Errorf("Invalid alias %s", name)
+	}
+	expect := "import (\n\tcryptorand \"crypto/rand\"\n\t\"fmt\"\n\t\"math/rand\"\n\n\t\"gopkg.in/yaml.v2\"\n)\n"
+	if s := imports.String(); s != expect {
+		t.Errorf("Invalid import block:\n%s", s)
+	}
+}
+
+func TestCodeImportSet(t *testing.T) {
+	target := types.NewPackage("example.com/foo", "foo")
+	mathRand := types.NewPackage("math/rand", "rand")
+	cryptoRand := types.NewPackage("crypto/rand", "rand")
+	imports := meta.NewImports(target)
+	c := meta.Code{}.WithImports(imports)
+	rnd := types.NewNamed(types.NewTypeName(0, mathRand, "Rand", nil), types.NewStruct(nil, nil), nil)
+	c = c.QPrintf(c.Qualifier(), "var r %s\n", types.NewPointer(rnd))
+	c = c.Append(meta.Code{}.Import(cryptoRand, target))
+	c = c.Printf("var _ = %s.Reader\n", imports.Name(cryptoRand))
+	if c.ImportSet(nil) != imports {
+		t.Fatal("Import set not attached")
+	}
+	if imports.Len() != 2 {
+		t.Errorf("Invalid number of imports %d", imports.Len())
+	}
+	expect := "var r *rand.Rand\nvar _ = cryptorand.Reader\n"
+	if c.String() != expect {
+		t.Errorf("Invalid code %q", c)
+	}
+}
@@ -0,0 +1,94 @@
+package meta
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// InterfaceBuilder builds interface types with multiple methods,
+// embedded interfaces and type set constraints.
+type InterfaceBuilder struct {
+	pkg      *types.Package
+	methods  []*types.Func
+	embedded []types.Type
+	err      error
+}
+
+// NewInterfaceBuilder creates an interface builder for methods of pkg.
+func NewInterfaceBuilder(pkg *types.Package) *InterfaceBuilder {
+	return &InterfaceBuilder{pkg: pkg}
+}
+
+// Method adds a method to the interface.
+// If variadic is set the last parameter must be a slice.
+func (b *InterfaceBuilder) Method(name string, params, results []*types.Var, variadic bool) *InterfaceBuilder {
+	if b.err != nil {
+		return b
+	}
+	for _, m := range b.methods {
+		if m.Name() == name {
+			b.err = fmt.Errorf("Duplicate method %s", name)
+			return b
+		}
+	}
+	if variadic {
+		if n := len(params); n == 0 {
+			b.err = fmt.Errorf("Variadic method %s has no parameters", name)
+			return b
+		} else if _, ok := params[n-1].Type().(*types.Slice); !ok {
+			b.err = fmt.Errorf("Variadic parameter of method %s is not a slice", name)
+			return b
+		}
+	}
+	sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), types.NewTuple(results...), variadic)
+	b.methods = append(b.methods, types.NewFunc(token.NoPos, b.pkg, name, sig))
+	return b
+}
+
+// Embed embeds types in the interface.
+// Embedded non-interface types restrict the type set of a constraint.
+func (b *InterfaceBuilder) Embed(embedded ...types.Type) *InterfaceBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.embedded = append(b.embedded, embedded...)
+	return b
+}
+
+// Union embeds a union of type terms in a constraint interface.
+func (b *InterfaceBuilder) Union(terms ...*types.Term) *InterfaceBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(terms) == 0 {
+		b.err = fmt.Errorf("Empty union")
+		return b
+	}
+	b.embedded = append(b.embedded, types.NewUnion(terms))
+	return b
+}
+
+// Interface creates the interface type.
+func (b *InterfaceBuilder) Interface() (*types.Interface, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	methods := make([]*types.Func, len(b.methods))
+	copy(methods, b.methods)
+	embedded := make([]types.Type, len(b.embedded))
+	copy(embedded, b.embedded)
+	return types.NewInterfaceType(methods, embedded).Complete(), nil
+}
+
+// InterfaceDecl creates a type declaration for an interface.
+func (b *Builder) InterfaceDecl(name string, iface *types.Interface) *ast.GenDecl {
+	return b.TypeDecl(name, b.Type(iface))
+}
+
+// InterfaceCode generates the type declaration of an interface.
+func InterfaceCode(name string, iface *types.Interface, q types.Qualifier) Code {
+	b := NewBuilder(q)
+	return b.Code(b.InterfaceDecl(name, iface))
+}
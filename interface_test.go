@@ -0,0 +1,76 @@
+package meta_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestInterfaceBuilder(t *testing.T) {
+	pkg := types.NewPackage("example.com/foo", "foo")
+	b := meta.NewBuilder(nil)
+	ctx := types.NewNamed(types.NewTypeName(0, types.NewPackage("context", "context"), "Context", nil), types.NewInterfaceType(nil, nil), nil)
+	iface, err := meta.NewInterfaceBuilder(pkg).
+		Embed(meta.ErrorInterface()).
+		Method("Get", []*types.Var{
+			b.Var("ctx", ctx),
+			b.Var("keys", types.NewSlice(types.Typ[types.String])),
+		}, []*types.Var{
+			b.Var("n", types.Typ[types.Int]),
+			b.Var("err", types.Universe.Lookup("error").Type()),
+		}, true).
+		Method("Reset", nil, nil, false).
+		Interface()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iface.NumMethods() != 3 {
+		t.Errorf("Invalid number of methods %d", iface.NumMethods())
+	}
+	c := meta.InterfaceCode("Getter", iface, func(p *types.Package) string { return p.Name() })
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := `type Getter interface {
+	interface {
+		Error() string
+	}
+	Get(ctx context.Context, keys ...string) (n int, err error)
+	Reset()
+}
+`
+	if c.String() != expect {
+		t.Errorf("Invalid interface:\n%s", c)
+	}
+	if len(c.Imports) != 1 || c.Imports[0].Path() != "context" {
+		t.Errorf("Invalid imports %v", c.Imports)
+	}
+
+	if _, err := meta.NewInterfaceBuilder(pkg).Method("Reset", nil, nil, false).Method("Reset", nil, nil, false).Interface(); err == nil {
+		t.Errorf("Expected duplicate method error")
+	}
+	if _, err := meta.NewInterfaceBuilder(pkg).Method("Get", []*types.Var{b.Var("n", types.Typ[types.Int])}, nil, true).Interface(); err == nil {
+		t.Errorf("Expected variadic error")
+	}
+}
+
+func TestInterfaceBuilderConstraint(t *testing.T) {
+	number, err := meta.NewInterfaceBuilder(nil).Union(
+		types.NewTerm(true, types.Typ[types.Int]),
+		types.NewTerm(true, types.Typ[types.Float64]),
+	).Interface()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number.IsMethodSet() {
+		t.Errorf("Constraint is a method set")
+	}
+	if terms := meta.TypeTerms(number); len(terms) != 2 {
+		t.Errorf("Invalid terms %v", terms)
+	}
+	c := meta.InterfaceCode("Number", number, nil)
+	if c.String() != "type Number interface {\n\t~int | ~float64\n}\n" {
+		t.Errorf("Invalid constraint:\n%s", c)
+	}
+}
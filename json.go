@@ -0,0 +1,236 @@
+package meta
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+var (
+	strconvPkg = types.NewPackage("strconv", "strconv")
+	stringsPkg = types.NewPackage("strings", "strings")
+)
+
+// JSONField is a struct field encoded by encoding/json.
+type JSONField struct {
+	Field
+	// Key is the name of the field in JSON objects.
+	Key string
+	// OmitEmpty is set if the field is omitted when empty.
+	OmitEmpty bool
+	// String is set if the field value is encoded inside a JSON string.
+	String bool
+}
+
+// JSONFields returns the fields of a struct encoded by encoding/json
+// in encoding order.
+func JSONFields(s *types.Struct) []JSONField {
+	visible := VisibleFields(s, "json")
+	fields := make([]JSONField, len(visible))
+	for i, f := range visible {
+		fields[i] = JSONField{Field: f}
+		fields[i].Key, _ = f.TagName("json")
+		if tag, ok := ParseTag(f.Tag, "json"); ok {
+			fields[i].OmitEmpty = tag.Params.Has("omitempty")
+			fields[i].String = tag.Params.Has("string") && jsonQuotable(f.Type())
+		}
+	}
+	return fields
+}
+
+// jsonQuotable checks if the string option applies to a type.
+// Like encoding/json it only dereferences unnamed pointer types
+// and ignores the option for marshalers.
+func jsonQuotable(t types.Type) bool {
+	t = Deref(t)
+	if jsonMarshaler(t) {
+		return false
+	}
+	_, ok := BasicInfo(t, types.IsBoolean|types.IsNumeric|types.IsString)
+	return ok
+}
+
+// jsonMarshaler checks if a type or a pointer to it implements
+// json.Marshaler or encoding.TextMarshaler.
+func jsonMarshaler(t types.Type) bool {
+	mset := types.NewMethodSet(types.NewPointer(t))
+	return mset.Lookup(nil, "MarshalJSON") != nil || mset.Lookup(nil, "MarshalText") != nil
+}
+
+// JSONCode generates MarshalJSON and UnmarshalJSON methods for a struct type
+// that follow the field rules of encoding/json without using reflection
+// for basic field types.
+//
+// The code must be generated into the package of the type.
+func JSONCode(named *types.Named, q types.Qualifier) (c Code) {
+	s, ok := Struct(named)
+	if !ok {
+		return c.Errorf("Type %s is not a struct", named)
+	}
+	if Generic(named) {
+		return c.Errorf("Generic type %s is not supported", named)
+	}
+	fields := JSONFields(s)
+	c = c.Import(named)
+	c = c.Append(jsonMarshal(named, fields, q))
+	c = c.Append(jsonUnmarshal(named, fields, q))
+	return c
+}
+
+func jsonMarshal(named *types.Named, fields []JSONField, q types.Qualifier) (c Code) {
+	marshal := qualifiedName(q, jsonPkg, "Marshal")
+	c = c.Printf("\n// MarshalJSON implements json.Marshaler.\n")
+	c = c.Printf("func (x %s) MarshalJSON() ([]byte, error) {\n", types.TypeString(named, q))
+	if len(fields) == 0 {
+		return c.Printf("\treturn []byte(\"{}\"), nil\n}\n")
+	}
+	c = c.Printf("\tbuf := make([]byte, 0, %d)\n", 16*len(fields)+2)
+	appenders := make([]string, len(fields))
+	needsData := false
+	for i, f := range fields {
+		if appenders[i] = jsonAppender(f.Type()); appenders[i] == "" {
+			needsData = true
+		} else {
+			c = c.Import(strconvPkg)
+		}
+	}
+	if needsData {
+		c = c.Import(jsonPkg)
+		c = c.Printf("\tvar (\n\t\tdata []byte\n\t\terr  error\n\t)\n")
+	}
+	for i, f := range fields {
+		x := f.Path.Get("x").String()
+		var conditions []string
+		if f.OmitEmpty {
+			if notEmpty := jsonNotEmpty(x, f.Type()); notEmpty != "" {
+				conditions = append(conditions, notEmpty)
+			}
+		}
+		var body Code
+		body = body.Printf("\tbuf = append(buf, %q...)\n", ","+jsonQuote(f.Key)+":")
+		switch appender := appenders[i]; {
+		case appender != "" && f.String:
+			body = body.Printf("\tbuf = append(buf, '\"')\n")
+			body = body.Printf("\tbuf = %s\n", fmt.Sprintf(qualifiedName(q, strconvPkg, appender), x))
+			body = body.Printf("\tbuf = append(buf, '\"')\n")
+		case appender != "":
+			body = body.Printf("\tbuf = %s\n", fmt.Sprintf(qualifiedName(q, strconvPkg, appender), x))
+		default:
+			body = body.Printf("\tif data, err = %s(%s); err != nil {\n\t\treturn nil, err\n\t}\n", marshal, x)
+			if _, ok := f.Type().Underlying().(*types.Pointer); ok && f.String {
+				// nil pointers are encoded as null without quotes
+				body = body.Printf("\tif string(data) != \"null\" {\n")
+				body = body.Printf("\t\tif data, err = %s(string(data)); err != nil {\n\t\t\treturn nil, err\n\t\t}\n\t}\n", marshal)
+			} else if f.String {
+				body = body.Printf("\tif data, err = %s(string(data)); err != nil {\n\t\treturn nil, err\n\t}\n", marshal)
+			}
+			body = body.Printf("\tbuf = append(buf, data...)\n")
+		}
+		c = c.Append(f.Path.Guard("x", body, conditions...))
+	}
+	c = c.Printf("\tif len(buf) == 0 {\n\t\treturn []byte(\"{}\"), nil\n\t}\n")
+	c = c.Printf("\tbuf[0] = '{'\n\treturn append(buf, '}'), nil\n}\n")
+	return c
+}
+
+// jsonAppender returns a strconv append call format for a field type.
+// It returns an empty string if the value is encoded with json.Marshal.
+func jsonAppender(t types.Type) string {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok || jsonMarshaler(t) {
+		return ""
+	}
+	switch info := b.Info(); {
+	case info&types.IsBoolean != 0:
+		return "AppendBool(buf, bool(%s))"
+	case info&types.IsInteger != 0 && info&types.IsUnsigned != 0:
+		return "AppendUint(buf, uint64(%s), 10)"
+	case info&types.IsInteger != 0:
+		return "AppendInt(buf, int64(%s), 10)"
+	default:
+		return ""
+	}
+}
+
+func jsonUnmarshal(named *types.Named, fields []JSONField, q types.Qualifier) (c Code) {
+	unmarshal := qualifiedName(q, jsonPkg, "Unmarshal")
+	rawMessage := qualifiedName(q, jsonPkg, "RawMessage")
+	equalFold := qualifiedName(q, stringsPkg, "EqualFold")
+	c = c.Import(jsonPkg)
+	c = c.Printf("\n// UnmarshalJSON implements json.Unmarshaler.\n")
+	c = c.Printf("func (x *%s) UnmarshalJSON(data []byte) error {\n", types.TypeString(named, q))
+	c = c.Printf("\tvar values map[string]%s\n", rawMessage)
+	c = c.Printf("\tif err := %s(data, &values); err != nil {\n\t\treturn err\n\t}\n", unmarshal)
+	if len(fields) == 0 {
+		return c.Printf("\treturn nil\n}\n")
+	}
+	c = c.Import(stringsPkg)
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = fmt.Sprintf("%q", f.Key)
+	}
+	c = c.Printf("\tfor key, raw := range values {\n")
+	// encoding/json matches keys case insensitively preferring exact matches
+	c = c.Printf("\t\tswitch key {\n\t\tcase %s:\n\t\tdefault:\n", strings.Join(keys, ", "))
+	c = c.Printf("\t\t\tfor _, k := range []string{%s} {\n", strings.Join(keys, ", "))
+	c = c.Printf("\t\t\t\tif %s(key, k) {\n\t\t\t\t\tkey = k\n\t\t\t\t\tbreak\n\t\t\t\t}\n\t\t\t}\n\t\t}\n", equalFold)
+	c = c.Printf("\t\tswitch key {\n")
+	for _, f := range fields {
+		c = c.Printf("\t\tcase %q:\n", f.Key)
+		c = c.Append(f.Path.Alloc("x", q))
+		x := f.Path.Get("x").String()
+		if f.String {
+			// null is not quoted and decodes like a field without the option
+			c = c.Printf("\t\t\tif string(raw) != \"null\" {\n")
+			c = c.Printf("\t\t\t\tvar s string\n")
+			c = c.Printf("\t\t\t\tif err := %s(raw, &s); err != nil {\n\t\t\t\t\treturn err\n\t\t\t\t}\n", unmarshal)
+			c = c.Printf("\t\t\t\traw = []byte(s)\n\t\t\t}\n")
+		}
+		c = c.Printf("\t\t\tif err := %s(raw, &%s); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n", unmarshal, x)
+	}
+	c = c.Printf("\t\t}\n\t}\n\treturn nil\n}\n")
+	return c
+}
+
+// jsonNotEmpty returns the condition for a non empty value with omitempty.
+func jsonNotEmpty(x string, t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return x
+		case u.Info()&types.IsString != 0:
+			return x + ` != ""`
+		case u.Info()&types.IsNumeric != 0:
+			return x + " != 0"
+		}
+	case *types.Slice, *types.Map:
+		return "len(" + x + ") != 0"
+	case *types.Array:
+		if u.Len() == 0 {
+			return "false"
+		}
+	case *types.Pointer, *types.Interface, *types.Chan, *types.Signature:
+		return x + " != nil"
+	}
+	return ""
+}
+
+// jsonQuote quotes a key as a JSON string.
+func jsonQuote(key string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range key {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r == '<' || r == '>' || r == '&':
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,132 @@
+package meta_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestJSONFields(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/json", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("json", "example.com/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := meta.Struct(pkg.LookupType("User"))
+	var keys []string
+	for _, f := range meta.JSONFields(s) {
+		keys = append(keys, f.Key)
+	}
+	expect := "id Created tags Visible extra name email Age score limit day next - parent"
+	if got := strings.Join(keys, " "); got != expect {
+		t.Errorf("Invalid JSON fields %q != %q", got, expect)
+	}
+}
+
+func TestJSONCode(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/json", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("json", "example.com/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := pkg.NewFile()
+	f.Add(meta.JSONCode(pkg.LookupType("User"), f.Qualifier()))
+	f.Add(meta.JSONCode(pkg.LookupType("Empty"), f.Qualifier()))
+	c := f.Code()
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"\t\"encoding/json\"\n\t\"strconv\"\n\t\"strings\"\n",
+		"\tbuf = append(buf, \",\\\"id\\\":\"...)\n\tbuf = strconv.AppendInt(buf, int64(x.Base.ID), 10)\n",
+		"\tif x.Meta != nil && len(x.Meta.Tags) != 0 {\n",
+		"\tif data, err = json.Marshal(x.Extra); err != nil {\n",
+		"\tbuf = append(buf, '\"')\n\tbuf = strconv.AppendUint(buf, uint64(x.Age), 10)\n",
+		"\t\tif data, err = json.Marshal(string(data)); err != nil {\n",
+		"\t\tcase \"tags\":\n\t\t\tif x.Meta == nil {\n\t\t\t\tx.Meta = new(Meta)\n\t\t\t}\n",
+		"\t\t\t\tif strings.EqualFold(key, k) {\n",
+		"func (x Empty) MarshalJSON() ([]byte, error) {\n\treturn []byte(\"{}\"), nil\n}\n",
+	} {
+		if !strings.Contains(c.String(), expect) {
+			t.Errorf("JSON code does not contain %q:\n%s", expect, c)
+		}
+	}
+	for _, unexpected := range []string{"Password", "secret"} {
+		if strings.Contains(c.String(), unexpected) {
+			t.Errorf("JSON code contains %q", unexpected)
+		}
+	}
+
+	if _, err := p.ParseFile("testdata/json/json_gen.go", c.Code); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Package("json", "example.com/json", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/json", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("json", "example.com/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := pkg.NewFile()
+	f.Add(meta.JSONCode(pkg.LookupType("User"), f.Qualifier()))
+	c := f.Code()
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	// compare with encoding/json
+	if out, err := runRoundTrip(t, "testdata/json", "example.com/json", c.Code, "json.go"); err != nil {
+		t.Errorf("Generated code does not match encoding/json: %s\n%s", err, out)
+	}
+}
+
+// runRoundTrip runs the roundtrip command of a fixture directory in a
+// temporary module with the fixture files and the generated code.
+func runRoundTrip(t *testing.T, fixture, module string, code []byte, filenames ...string) ([]byte, error) {
+	t.Helper()
+	gocmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"go.mod":       []byte("module " + module + "\n\ngo 1.21\n"),
+		"generated.go": code,
+	}
+	for _, name := range append(filenames, "roundtrip/main.go") {
+		data, err := os.ReadFile(filepath.Join(fixture, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = data
+	}
+	for name, data := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(gocmd, "run", "./roundtrip")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	return cmd.CombinedOutput()
+}
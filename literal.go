@@ -0,0 +1,161 @@
+package meta
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"strconv"
+)
+
+// ZeroValue returns the zero value expression of a type.
+//
+// Composite types are printed as empty composite literals using q and their
+// imports are recorded. Type parameters use *new(T).
+func ZeroValue(t types.Type, q types.Qualifier) (c Code) {
+	if t == nil {
+		return c.Errorf("Invalid nil type")
+	}
+	if _, ok := TypeParam(t); ok {
+		return c.Printf("*new(%s)", types.TypeString(t, q))
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return c.Print("false")
+		case u.Info()&types.IsString != 0:
+			return c.Print(`""`)
+		case u.Info()&types.IsNumeric != 0:
+			return c.Print("0")
+		default:
+			return c.Print("nil")
+		}
+	case *types.Struct, *types.Array:
+		return c.QPrintf(q, "%s{}", t)
+	default:
+		return c.Print("nil")
+	}
+}
+
+// Literal returns the literal expression of a constant value for a type.
+//
+// Values of named types are converted to the type so the expression
+// is typed. The value must be representable by the type.
+// Named types are qualified by their package name so the code does not
+// compile in the package of the type; use QLiteral to generate code there.
+func Literal(t types.Type, v constant.Value) (c Code) {
+	return literal(t, v, nil)
+}
+
+// QLiteral is like Literal but qualifies named types with q.
+func QLiteral(q types.Qualifier, t types.Type, v constant.Value) Code {
+	return literal(t, v, q)
+}
+
+func literal(t types.Type, v constant.Value, q types.Qualifier) (c Code) {
+	if t == nil || v == nil {
+		return c.Errorf("Invalid literal %v of type %v", v, t)
+	}
+	b, ok := Basic(t)
+	if !ok {
+		return c.Errorf("Type %s cannot have a constant value", t)
+	}
+	lit, err := basicLiteral(b, v)
+	if err != nil {
+		return c.Error(err)
+	}
+	if _, basic := t.(*types.Basic); basic {
+		return c.Print(lit)
+	}
+	if q == nil {
+		q = func(pkg *types.Package) string {
+			return pkg.Name()
+		}
+	}
+	return c.QPrintf(q, "%s(%s)", t, lit)
+}
+
+func basicLiteral(b *types.Basic, v constant.Value) (string, error) {
+	info := b.Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		if v.Kind() == constant.Bool {
+			return strconv.FormatBool(constant.BoolVal(v)), nil
+		}
+	case info&types.IsString != 0:
+		if v.Kind() == constant.String {
+			return strconv.Quote(constant.StringVal(v)), nil
+		}
+	case info&types.IsInteger != 0:
+		if v := constant.ToInt(v); v.Kind() == constant.Int && representableInt(b, v) {
+			return v.ExactString(), nil
+		}
+	case info&types.IsFloat != 0:
+		v := constant.ToFloat(v)
+		f, ok := representableFloat(b, v)
+		if ok && v.Kind() == constant.Int {
+			return v.ExactString(), nil
+		} else if ok {
+			return strconv.FormatFloat(f, 'g', -1, 64), nil
+		}
+	case info&types.IsComplex != 0:
+		if v := constant.ToComplex(v); v.Kind() == constant.Complex {
+			re, okRe := representableFloat(b, constant.Real(v))
+			im, okIm := representableFloat(b, constant.Imag(v))
+			if okRe && okIm {
+				return fmt.Sprintf("complex(%s, %s)",
+					strconv.FormatFloat(re, 'g', -1, 64),
+					strconv.FormatFloat(im, 'g', -1, 64),
+				), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("Constant %s cannot be represented by %s", v, b)
+}
+
+// representableFloat converts a float constant to float64 and checks that
+// it does not overflow a float type or the parts of a complex type.
+// Values that are not exact are rounded like the compiler does.
+func representableFloat(b *types.Basic, v constant.Value) (float64, bool) {
+	if k := v.Kind(); k != constant.Int && k != constant.Float {
+		return 0, false
+	}
+	f, _ := constant.Float64Val(v)
+	if math.IsInf(f, 0) {
+		return 0, false
+	}
+	switch b.Kind() {
+	case types.Float32, types.Complex64:
+		if f32, _ := constant.Float32Val(v); math.IsInf(float64(f32), 0) {
+			return 0, false
+		}
+	}
+	return f, true
+}
+
+// representableInt checks if an integer constant fits in an integer type
+// assuming 64-bit int, uint and uintptr.
+func representableInt(b *types.Basic, v constant.Value) bool {
+	bits, signed := 64, b.Info()&types.IsUnsigned == 0
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		bits = 8
+	case types.Int16, types.Uint16:
+		bits = 16
+	case types.Int32, types.Uint32:
+		bits = 32
+	case types.UntypedInt, types.UntypedRune:
+		return true
+	}
+	if !signed {
+		return constant.Sign(v) >= 0 && constant.BitLen(v) <= bits
+	}
+	if constant.Sign(v) < 0 {
+		// -1 << (bits-1) is the minimum value
+		v = constant.BinaryOp(v, token.ADD, constant.MakeInt64(1))
+		v = constant.UnaryOp(token.SUB, v, 0)
+	}
+	return constant.BitLen(v) < bits
+}
@@ -0,0 +1,90 @@
+package meta_test
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestZeroValue(t *testing.T) {
+	pkg := types.NewPackage("example.com/foo", "foo")
+	q := types.RelativeTo(pkg)
+	named := func(name string, u types.Type) *types.Named {
+		return types.NewNamed(types.NewTypeName(0, pkg, name, nil), u, nil)
+	}
+	tparam := types.NewTypeParam(types.NewTypeName(0, pkg, "T", nil), types.NewInterfaceType(nil, nil))
+	for _, tc := range []struct {
+		Type    types.Type
+		Expect  string
+		Imports int
+	}{
+		{types.Typ[types.String], `""`, 0},
+		{types.Typ[types.Float64], "0", 0},
+		{named("Flag", types.Typ[types.Bool]), "false", 0},
+		{named("Point", types.NewStruct(nil, nil)), "Point{}", 1},
+		{types.NewArray(types.Typ[types.Int], 3), "[3]int{}", 0},
+		{types.NewPointer(named("Point", types.NewStruct(nil, nil))), "nil", 0},
+		{types.NewInterfaceType(nil, nil), "nil", 0},
+		{tparam, "*new(T)", 0},
+	} {
+		c := meta.ZeroValue(tc.Type, q)
+		if err := c.Err(); err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if c.String() != tc.Expect {
+			t.Errorf("Invalid zero value of %s: %s", tc.Type, c)
+		}
+		if len(c.Imports) != tc.Imports {
+			t.Errorf("Invalid imports for %s: %v", tc.Type, c.Imports)
+		}
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	pkg := types.NewPackage("example.com/foo", "foo")
+	weekday := types.NewNamed(types.NewTypeName(0, pkg, "Weekday", nil), types.Typ[types.Uint8], nil)
+	for _, tc := range []struct {
+		Type   types.Type
+		Value  constant.Value
+		Expect string
+	}{
+		{types.Typ[types.String], constant.MakeString("foo\n"), `"foo\n"`},
+		{types.Typ[types.Bool], constant.MakeBool(true), "true"},
+		{types.Typ[types.Int8], constant.MakeInt64(-128), "-128"},
+		{types.Typ[types.Float64], constant.MakeFloat64(0.5), "0.5"},
+		{types.Typ[types.Float32], constant.MakeInt64(2), "2"},
+		{types.Typ[types.Float32], constant.MakeFloat64(0.1), "0.1"},
+		{types.Typ[types.Complex64], constant.MakeImag(constant.MakeInt64(2)), "complex(0, 2)"},
+		{weekday, constant.MakeInt64(3), "foo.Weekday(3)"},
+	} {
+		c := meta.Literal(tc.Type, tc.Value)
+		if err := c.Err(); err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if c.String() != tc.Expect {
+			t.Errorf("Invalid literal %s of %s: %s", tc.Value, tc.Type, c)
+		}
+	}
+	for _, tc := range []struct {
+		Type  types.Type
+		Value constant.Value
+	}{
+		{types.Typ[types.Int8], constant.MakeInt64(128)},
+		{weekday, constant.MakeInt64(-1)},
+		{types.Typ[types.String], constant.MakeInt64(1)},
+		{types.Typ[types.Float64], constant.MakeFromLiteral("1e400", token.FLOAT, 0)},
+		{types.Typ[types.Float32], constant.MakeFloat64(1e39)},
+		{types.Typ[types.Complex64], constant.MakeImag(constant.MakeFloat64(1e39))},
+		{types.NewSlice(types.Typ[types.Int]), constant.MakeInt64(1)},
+	} {
+		if c := meta.Literal(tc.Type, tc.Value); c.Err() == nil {
+			t.Errorf("Expected error for %s of %s: %s", tc.Value, tc.Type, c)
+		}
+	}
+	if c := meta.QLiteral(types.RelativeTo(pkg), weekday, constant.MakeInt64(3)); c.String() != "Weekday(3)" {
+		t.Errorf("Invalid qualified literal %s", c)
+	}
+}
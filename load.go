@@ -0,0 +1,198 @@
+package meta
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// LoadPackage loads and type-checks the packages matching patterns.
+//
+// Patterns are passed to `go list` so both import paths and `./...` style
+// patterns are accepted.
+func LoadPackage(patterns ...string) ([]*Package, error) {
+	return NewParser(parser.ParseComments).LoadPackage(patterns...)
+}
+
+// LoadPackage loads and type-checks the packages matching patterns.
+//
+// Packages are checked in dependency order so packages loaded by the same
+// call share the type-checked packages they import from each other.
+func (p *Parser) LoadPackage(patterns ...string) ([]*Package, error) {
+	listed, err := goList(p.ctxt, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	loaded := make(map[*listPackage]*Package, len(listed))
+	for _, lp := range dependencyOrder(listed) {
+		pkg, err := p.load(lp)
+		if err != nil {
+			return nil, err
+		}
+		loaded[lp] = pkg
+	}
+	packages := make([]*Package, len(listed))
+	for i, lp := range listed {
+		packages[i] = loaded[lp]
+	}
+	return packages, nil
+}
+
+// dependencyOrder sorts listed packages so that each package comes after
+// the listed packages it imports directly or through other packages.
+func dependencyOrder(listed []*listPackage) []*listPackage {
+	index := make(map[string]*listPackage, len(listed))
+	for _, lp := range listed {
+		index[lp.ImportPath] = lp
+	}
+	sorted := make([]*listPackage, 0, len(listed))
+	visited := make(map[*listPackage]bool, len(listed))
+	var visit func(lp *listPackage)
+	visit = func(lp *listPackage) {
+		if visited[lp] {
+			return
+		}
+		visited[lp] = true
+		for _, path := range lp.Deps {
+			if dep, ok := index[path]; ok {
+				visit(dep)
+			}
+		}
+		sorted = append(sorted, lp)
+	}
+	for _, lp := range listed {
+		visit(lp)
+	}
+	return sorted
+}
+
+// LoadTestPackages loads the test variants of the packages matching patterns.
+//
+// For each package the result holds the package with its in-package test
+// files followed by the external test package if one exists.
+func (p *Parser) LoadTestPackages(patterns ...string) ([]*Package, error) {
+	listed, err := goList(p.ctxt, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	packages := make([]*Package, 0, 2*len(listed))
+	for _, lp := range listed {
+		bp, err := p.ctxt.ImportDir(lp.Dir, 0)
+		if err != nil {
+			return nil, err
+		}
+		files, err := p.parseFiles(bp.Dir, bp.GoFiles, bp.CgoFiles, bp.TestGoFiles)
+		if err != nil {
+			return nil, err
+		}
+		test, err := p.checkFiles(p.importer, lp.ImportPath, files)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, test)
+		if len(bp.XTestGoFiles) == 0 {
+			continue
+		}
+		if files, err = p.parseFiles(bp.Dir, bp.XTestGoFiles); err != nil {
+			return nil, err
+		}
+		xtest, err := p.checkFiles(p.testImporter(test.pkg), lp.ImportPath+"_test", files)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, xtest)
+	}
+	return packages, nil
+}
+
+func (p *Parser) load(lp *listPackage) (*Package, error) {
+	bp, err := p.ctxt.ImportDir(lp.Dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	files, err := p.parseFiles(bp.Dir, bp.GoFiles, bp.CgoFiles)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := p.checkFiles(p.importer, lp.ImportPath, files)
+	if err != nil {
+		return nil, err
+	}
+	if imp, ok := p.importer.(*Importer); ok {
+		// Packages importing this one use the same *types.Package
+		imp.register(bp.Dir, pkg.pkg)
+	}
+	return pkg, nil
+}
+
+func (p *Parser) parseFiles(dir string, filenames ...[]string) ([]*ast.File, error) {
+	var files []*ast.File
+	for _, names := range filenames {
+		for _, name := range names {
+			f, err := parser.ParseFile(p.fset, filepath.Join(dir, name), nil, p.mode)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+type listPackage struct {
+	Dir        string
+	ImportPath string
+	Deps       []string
+	Error      *struct {
+		Err string
+	}
+}
+
+func goList(ctxt *build.Context, patterns ...string) ([]*listPackage, error) {
+	args := []string{"list", "-e", "-json=Dir,ImportPath,Deps,Error"}
+	if len(ctxt.BuildTags) > 0 {
+		args = append(args, "-tags="+strings.Join(ctxt.BuildTags, ","))
+	}
+	args = append(args, "--")
+	args = append(args, patterns...)
+	cmd := exec.Command(filepath.Join(ctxt.GOROOT, "bin", "go"), args...)
+	cmd.Dir = ctxt.Dir
+	cgo := "0"
+	if ctxt.CgoEnabled {
+		cgo = "1"
+	}
+	cmd.Env = append(os.Environ(),
+		"GOOS="+ctxt.GOOS,
+		"GOARCH="+ctxt.GOARCH,
+		"CGO_ENABLED="+cgo,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list: %s\n%s", err, stderr.Bytes())
+	}
+	var listed []*listPackage
+	dec := json.NewDecoder(&stdout)
+	for {
+		lp := listPackage{}
+		if err := dec.Decode(&lp); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if lp.Error != nil {
+			return nil, fmt.Errorf("Package %s: %s", lp.ImportPath, lp.Error.Err)
+		}
+		listed = append(listed, &lp)
+	}
+	return listed, nil
+}
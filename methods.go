@@ -0,0 +1,80 @@
+package meta
+
+import (
+	"go/types"
+)
+
+// Implementation is a defined type that implements an interface.
+type Implementation struct {
+	*types.Named
+	// Pointer is set if only the pointer type *T implements the interface.
+	Pointer bool
+}
+
+// Type returns the type that implements the interface, either T or *T.
+func (impl Implementation) Type() types.Type {
+	if impl.Pointer {
+		return types.NewPointer(impl.Named)
+	}
+	return impl.Named
+}
+
+// MethodSets returns the method sets of T and *T.
+func (p *Package) MethodSets(t types.Type) (value, pointer *types.MethodSet) {
+	value = types.NewMethodSet(t)
+	if _, isPointer := t.(*types.Pointer); isPointer || IsInterface(t) {
+		return value, value
+	}
+	return value, types.NewMethodSet(types.NewPointer(t))
+}
+
+// Interfaces returns the interface types defined in the package.
+func (p *Package) Interfaces() (ifaces []*types.Named) {
+	p.DefinedTypes(func(t types.Type) bool {
+		if named, ok := t.(*types.Named); ok && IsInterface(named) {
+			ifaces = append(ifaces, named)
+		}
+		return false
+	})
+	return
+}
+
+// Implementations returns the types defined in the package that implement
+// an interface either by value or by pointer receiver.
+// Interface and generic types are not included.
+func (p *Package) Implementations(iface types.Type) (impls []Implementation) {
+	i, ok := iface.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	p.DefinedTypes(func(t types.Type) bool {
+		named, ok := t.(*types.Named)
+		if !ok || IsInterface(named) || Generic(named) {
+			return false
+		}
+		if types.Implements(named, i) {
+			impls = append(impls, Implementation{named, false})
+		} else if types.Implements(types.NewPointer(named), i) {
+			impls = append(impls, Implementation{named, true})
+		}
+		return false
+	})
+	return
+}
+
+// Satisfies returns the interfaces defined in the package that t implements.
+// If pointer is set the interfaces implemented by *t are returned.
+func (p *Package) Satisfies(t types.Type, pointer bool) (ifaces []*types.Named) {
+	if pointer {
+		t = types.NewPointer(t)
+	}
+	for _, iface := range p.Interfaces() {
+		if Generic(iface) {
+			continue
+		}
+		if types.Implements(t, iface.Underlying().(*types.Interface)) {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package meta_test
+
+import (
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestPackageMethods(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/methods", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("methods", "example.com/methods", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifaces := pkg.Interfaces(); len(ifaces) != 3 {
+		t.Errorf("Invalid interfaces %v", ifaces)
+	}
+	impls := pkg.Implementations(pkg.LookupType("Namer"))
+	if len(impls) != 2 {
+		t.Fatalf("Invalid implementations %v", impls)
+	}
+	if impls[0].Obj().Name() != "Value" || impls[0].Pointer {
+		t.Errorf("Invalid value implementation %v", impls[0])
+	}
+	if impls[1].Obj().Name() != "Pointer" || !impls[1].Pointer {
+		t.Errorf("Invalid pointer implementation %v", impls[1])
+	}
+	if impls[1].Type().String() != "*example.com/methods.Pointer" {
+		t.Errorf("Invalid implementation type %s", impls[1].Type())
+	}
+
+	ptr := pkg.LookupType("Pointer")
+	value, pointer := pkg.MethodSets(ptr)
+	if value.Len() != 0 || pointer.Len() != 2 {
+		t.Errorf("Invalid method sets %s %s", value, pointer)
+	}
+	if ifaces := pkg.Satisfies(ptr, false); len(ifaces) != 0 {
+		t.Errorf("Invalid interfaces satisfied by value %v", ifaces)
+	}
+	if ifaces := pkg.Satisfies(ptr, true); len(ifaces) != 3 {
+		t.Errorf("Invalid interfaces satisfied by pointer %v", ifaces)
+	}
+}
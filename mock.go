@@ -0,0 +1,217 @@
+package meta
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	syncPkg   = types.NewPackage("sync", "sync")
+	mutexType = types.NewNamed(types.NewTypeName(token.NoPos, syncPkg, "Mutex", nil), types.NewStruct(nil, nil), nil)
+)
+
+// Mocks generates mock implementations of interfaces defined in a package.
+//
+// For an interface Foo the mock FooMock has a FooFunc field for each method
+// that is called by the method, and records the arguments of each call.
+// Recorded calls and call counts are safe for concurrent use.
+// If q is nil the package qualifier is used.
+func Mocks(pkg *Package, q types.Qualifier, names ...string) (c Code) {
+	if q == nil {
+		q = pkg.Qualifier()
+	}
+	for _, name := range names {
+		named := pkg.LookupType(name)
+		if named == nil {
+			return c.Errorf("Interface %s not found in package %s", name, pkg.Path())
+		}
+		if c = c.Append(Mock(named, q)); c.Err() != nil {
+			return c
+		}
+	}
+	return c
+}
+
+// Mock generates a mock implementation of a named interface type.
+func Mock(named *types.Named, q types.Qualifier) (c Code) {
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok {
+		return c.Errorf("Type %s is not an interface", named)
+	}
+	if Generic(named) {
+		return c.Errorf("Generic interface %s is not supported", named)
+	}
+	if !iface.IsMethodSet() {
+		return c.Errorf("Constraint interface %s cannot be mocked", named)
+	}
+	methods := make([]*types.Func, iface.NumMethods())
+	for i := range methods {
+		methods[i] = iface.Method(i)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Name() < methods[j].Name()
+	})
+
+	mock := named.Obj().Name() + "Mock"
+	c = c.Import(named, mutexType)
+	c = c.Printf("// %s is a mock implementation of %s.\n", mock, types.TypeString(named, q))
+	c = c.Printf("type %s struct {\n", mock)
+	for _, m := range methods {
+		sig := m.Type().(*types.Signature)
+		c = c.Import(sig)
+		c = c.Printf("\t%sFunc func%s\n", m.Name(), signatureString(sig, nil, q))
+	}
+	c = c.Printf("\n\tmu %s\n\tcalls struct {\n", types.TypeString(mutexType, q))
+	for _, m := range methods {
+		c = c.Printf("\t\t%s []%s%sCall\n", m.Name(), mock, m.Name())
+	}
+	c = c.Printf("\t}\n}\n")
+
+	for _, m := range methods {
+		c = c.Append(mockMethod(mock, m, q))
+	}
+	return c
+}
+
+func mockMethod(mock string, m *types.Func, q types.Qualifier) (c Code) {
+	sig := m.Type().(*types.Signature)
+	params := paramNames(sig.Params())
+	recv := "m"
+	for _, name := range params {
+		if name == recv {
+			recv = "_m"
+		}
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		if sig.Results().At(i).Name() == recv {
+			recv = "_m"
+		}
+	}
+	call := mock + m.Name() + "Call"
+	fields := make([]string, len(params))
+	for i, name := range params {
+		fields[i] = fieldName(name, i)
+	}
+
+	c = c.Printf("\n// %s records a call to %s.%s.\n", call, mock, m.Name())
+	c = c.Printf("type %s struct {\n", call)
+	for i, name := range fields {
+		c = c.Printf("\t%s %s\n", name, types.TypeString(sig.Params().At(i).Type(), q))
+	}
+	c = c.Printf("}\n")
+
+	c = c.Printf("\n// %s calls %sFunc and records the call.\n", m.Name(), m.Name())
+	c = c.Printf("func (%s *%s) %s%s {\n", recv, mock, m.Name(), signatureString(sig, params, q))
+	c = c.Printf("\tif %s.%sFunc == nil {\n", recv, m.Name())
+	c = c.Printf("\t\tpanic(%q)\n", mock+"."+m.Name()+"Func is nil")
+	c = c.Printf("\t}\n")
+	c = c.Printf("\t%s.mu.Lock()\n", recv)
+	values := make([]string, len(params))
+	for i, name := range params {
+		values[i] = fields[i] + ": " + name
+	}
+	c = c.Printf("\t%s.calls.%s = append(%s.calls.%s, %s{%s})\n", recv, m.Name(), recv, m.Name(), call, strings.Join(values, ", "))
+	c = c.Printf("\t%s.mu.Unlock()\n", recv)
+	args := strings.Join(params, ", ")
+	if sig.Variadic() {
+		args += "..."
+	}
+	if sig.Results().Len() > 0 {
+		c = c.Printf("\treturn %s.%sFunc(%s)\n", recv, m.Name(), args)
+	} else {
+		c = c.Printf("\t%s.%sFunc(%s)\n", recv, m.Name(), args)
+	}
+	c = c.Printf("}\n")
+
+	c = c.Printf("\n// %sCalls returns the recorded calls to %s.\n", m.Name(), m.Name())
+	c = c.Printf("func (m *%s) %sCalls() []%s {\n", mock, m.Name(), call)
+	c = c.Printf("\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n")
+	c = c.Printf("\treturn append([]%s(nil), m.calls.%s...)\n", call, m.Name())
+	c = c.Printf("}\n")
+
+	c = c.Printf("\n// %sCallCount returns the number of calls to %s.\n", m.Name(), m.Name())
+	c = c.Printf("func (m *%s) %sCallCount() int {\n", mock, m.Name())
+	c = c.Printf("\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n")
+	c = c.Printf("\treturn len(m.calls.%s)\n", m.Name())
+	c = c.Printf("}\n")
+	return c
+}
+
+// paramNames returns unique names for the parameters of a signature.
+func paramNames(params *types.Tuple) []string {
+	names := make([]string, params.Len())
+	used := make(map[string]bool, len(names))
+	for i := range names {
+		if name := params.At(i).Name(); name != "" && name != "_" {
+			names[i] = name
+			used[name] = true
+		}
+	}
+	for i, name := range names {
+		if name != "" {
+			continue
+		}
+		for j := i; ; j++ {
+			name = "p" + strconv.Itoa(j)
+			if !used[name] {
+				break
+			}
+		}
+		names[i] = name
+		used[name] = true
+	}
+	return names
+}
+
+// fieldName exports a parameter name for use as a struct field.
+func fieldName(name string, i int) string {
+	r := []rune(name)
+	if len(r) == 0 || !unicode.IsLetter(r[0]) {
+		return fmt.Sprintf("Arg%d", i)
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// signatureString prints a signature using names for its parameters.
+// If names is nil the parameter names of the signature are used.
+func signatureString(sig *types.Signature, names []string, q types.Qualifier) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		v := params.At(i)
+		name := v.Name()
+		if names != nil {
+			name = names[i]
+		}
+		if name != "" {
+			sb.WriteString(name)
+			sb.WriteByte(' ')
+		}
+		if s, ok := v.Type().(*types.Slice); ok && sig.Variadic() && i == params.Len()-1 {
+			sb.WriteString("...")
+			sb.WriteString(types.TypeString(s.Elem(), q))
+		} else {
+			sb.WriteString(types.TypeString(v.Type(), q))
+		}
+	}
+	sb.WriteByte(')')
+	if results := sig.Results(); results.Len() > 0 {
+		sb.WriteByte(' ')
+		tuple := types.TypeString(results, q)
+		if results.Len() == 1 && results.At(0).Name() == "" {
+			tuple = strings.TrimSuffix(strings.TrimPrefix(tuple, "("), ")")
+		}
+		sb.WriteString(tuple)
+	}
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+package meta_test
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestMocks(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/mock", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("mock", "example.com/mock", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := pkg.NewFile()
+	f.Add(meta.Mocks(pkg, f.Qualifier(), "Store"))
+	c := f.Code()
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"\"context\"\n\t\"sync\"\n",
+		"GetFunc    func(ctx context.Context, key Key) (string, error)",
+		"DeleteFunc func(ctx context.Context, keys ...Key) (n int, err error)",
+		"func (m *StoreMock) Set(p0 context.Context, p1 Key, p2 string) error {",
+		"func (m *StoreMock) DeleteCallCount() int {",
+	} {
+		if !strings.Contains(c.String(), expect) {
+			t.Errorf("Mock does not contain %q:\n%s", expect, c)
+		}
+	}
+
+	// type-check the mock with the package
+	if _, err := p.ParseFile("testdata/mock/mock_gen.go", c.Code); err != nil {
+		t.Fatal(err)
+	}
+	checked, err := p.Package("mock", "example.com/mock", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := checked.LookupType("StoreMock")
+	store := checked.LookupType("Store").Underlying().(*types.Interface)
+	if !types.Implements(types.NewPointer(mock), store) {
+		t.Errorf("Mock does not implement interface")
+	}
+}
@@ -0,0 +1,137 @@
+package meta
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output writes generated files to disk.
+//
+// Files are only written if their content changed so that rewriting
+// identical files does not invalidate build caches or trigger file watchers.
+type Output struct {
+	// Dir is the directory relative filenames are resolved against.
+	Dir string
+	// Perm is the permissions of new files, defaults to 0644.
+	Perm os.FileMode
+	// Check enables check mode where no files are written and files that
+	// differ from the generated code are reported as stale.
+	Check   bool
+	changed []string
+	stale   StaleFiles
+}
+
+// StaleFile is a generated file that is not up to date.
+type StaleFile struct {
+	Filename string
+	// Diff is a unified diff from the file on disk to the generated code.
+	Diff string
+}
+
+// StaleFiles is a list of stale files found in check mode.
+type StaleFiles []StaleFile
+
+func (files StaleFiles) Error() string {
+	var sb strings.Builder
+	sb.WriteString("Generated files are not up to date:")
+	for _, f := range files {
+		sb.WriteString("\n\t")
+		sb.WriteString(f.Filename)
+	}
+	return sb.String()
+}
+
+// WriteFile renders a file and writes it to filename if its content changed.
+func (o *Output) WriteFile(filename string, f *File) (bool, error) {
+	return o.WriteCode(filename, f.Code())
+}
+
+// WriteCode formats code and writes it to filename if its content changed.
+func (o *Output) WriteCode(filename string, c Code) (bool, error) {
+	if c = c.Format(); c.Err() != nil {
+		return false, c.Err()
+	}
+	filename = o.path(filename)
+	data, err := os.ReadFile(filename)
+	if err == nil && bytes.Equal(data, c.Code) {
+		return false, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if o.Check {
+		name := strings.TrimPrefix(filepath.ToSlash(filename), "/")
+		o.stale = append(o.stale, StaleFile{
+			Filename: filename,
+			Diff:     unifiedDiff("a/"+name, "b/"+name, data, c.Code),
+		})
+		return true, nil
+	}
+	perm := o.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := writeFileAtomic(filename, c.Code, perm); err != nil {
+		return false, err
+	}
+	o.changed = append(o.changed, filename)
+	return true, nil
+}
+
+// Changed returns the files that were written.
+func (o *Output) Changed() []string {
+	return o.changed
+}
+
+// Stale returns the files that are not up to date in check mode.
+func (o *Output) Stale() StaleFiles {
+	return o.stale
+}
+
+// Err returns an error if stale files were found in check mode.
+func (o *Output) Err() error {
+	if len(o.stale) == 0 {
+		return nil
+	}
+	return o.stale
+}
+
+func (o *Output) path(filename string) string {
+	if o.Dir == "" || filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(o.Dir, filename)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to filename.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
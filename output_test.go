@@ -0,0 +1,148 @@
+package meta_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestOutput(t *testing.T) {
+	dir, err := os.MkdirTemp("", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := meta.Output{Dir: dir}
+	code := meta.Printf("package foo\nvar   x = 1\n")
+	for i, expect := range []bool{true, false} {
+		changed, err := out.WriteCode("foo.go", code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != expect {
+			t.Errorf("Invalid changed %t on write %d", changed, i)
+		}
+	}
+	if changed := out.Changed(); len(changed) != 1 || changed[0] != filepath.Join(dir, "foo.go") {
+		t.Errorf("Invalid changed files %v", changed)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "foo.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "package foo\n\nvar x = 1\n" {
+		t.Errorf("Invalid file content %q", data)
+	}
+	if _, err := out.WriteCode("bar.go", meta.Printf("package foo\nvar x =")); err == nil {
+		t.Errorf("Expected format error")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Invalid files in output directory %d", len(entries))
+	}
+}
+
+func TestOutputCheck(t *testing.T) {
+	dir, err := os.MkdirTemp("", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "foo.go")
+	if err := os.WriteFile(filename, []byte("package foo\n\nvar x = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := meta.Output{Check: true}
+	if _, err := out.WriteCode(filename, meta.Printf("package foo\nvar x = 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Err(); err != nil {
+		t.Errorf("Unexpected stale files %s", err)
+	}
+	if _, err := out.WriteCode(filename, meta.Printf("package foo\nvar x = 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.WriteCode(filepath.Join(dir, "bar.go"), meta.Printf("package foo\n")); err != nil {
+		t.Fatal(err)
+	}
+	stale := out.Stale()
+	if len(stale) != 2 {
+		t.Fatalf("Invalid stale files %v", stale)
+	}
+	name := strings.TrimPrefix(filepath.ToSlash(filename), "/")
+	expect := "--- a/" + name + "\n+++ b/" + name + "\n" +
+		"@@ -1,3 +1,3 @@\n package foo\n \n-var x = 1\n+var x = 2\n"
+	if stale[0].Diff != expect {
+		t.Errorf("Invalid diff:\n%s", stale[0].Diff)
+	}
+	if out.Err() == nil {
+		t.Errorf("Expected stale files error")
+	}
+	if len(out.Changed()) != 0 {
+		t.Errorf("Files written in check mode")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Files written in check mode")
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil || string(data) != "package foo\n\nvar x = 1\n" {
+		t.Errorf("File modified in check mode")
+	}
+}
+
+func TestOutputCheckLargeDiff(t *testing.T) {
+	dir, err := os.MkdirTemp("", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var a, b strings.Builder
+	for i := 0; i < 8000; i++ {
+		fmt.Fprintf(&a, "var a%d = %d\n", i, i)
+		fmt.Fprintf(&b, "var b%d = %d\n", i, i)
+	}
+	filename := filepath.Join(dir, "foo.go")
+	if err := os.WriteFile(filename, []byte(a.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	out := meta.Output{Check: true}
+	// missing file
+	if _, err := out.WriteCode(filepath.Join(dir, "missing.go"), meta.Printf("%s", b.String())); err != nil {
+		t.Fatal(err)
+	}
+	// every line changed
+	if _, err := out.WriteCode(filename, meta.Printf("%s", b.String())); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 64<<20 {
+		t.Errorf("Diff allocated %d bytes", alloc)
+	}
+	stale := out.Stale()
+	if len(stale) != 2 {
+		t.Fatalf("Invalid stale files %v", stale)
+	}
+	if !strings.Contains(stale[0].Diff, "@@ -0,0 +1,8000 @@\n+var b0 = 0\n") {
+		t.Errorf("Invalid diff of missing file:\n%.200s", stale[0].Diff)
+	}
+	if !strings.Contains(stale[1].Diff, "@@ -1,8000 +1,8000 @@\n-var a0 = 0\n") {
+		t.Errorf("Invalid diff of changed file:\n%.200s", stale[1].Diff)
+	}
+}
@@ -3,7 +3,7 @@ package meta
 import (
 	"fmt"
 	"go/ast"
-	"go/importer"
+	"go/build"
 	"go/parser"
 	"go/printer"
 	"go/token"
@@ -14,11 +14,56 @@ import (
 )
 
 type Package struct {
-	pkg   *types.Package
-	fset  *token.FileSet
-	info  types.Info
-	files []*ast.File
-	qual  types.Qualifier
+	pkg    *types.Package
+	fset   *token.FileSet
+	info   types.Info
+	files  []*ast.File
+	qual   types.Qualifier
+	errors TypeErrors
+}
+
+// Errors returns the type errors collected when the package was checked
+// with CheckAllErrors.
+func (p *Package) Errors() TypeErrors {
+	return p.errors
+}
+
+// Err returns an error if the package has type errors.
+func (p *Package) Err() error {
+	if len(p.errors) == 0 {
+		return nil
+	}
+	return p.errors
+}
+
+// Types returns the type-checked package.
+func (p *Package) Types() *types.Package {
+	return p.pkg
+}
+
+// Info returns the type information recorded when checking the package.
+func (p *Package) Info() *types.Info {
+	return &p.info
+}
+
+// FileSet returns the file set used to parse the package files.
+func (p *Package) FileSet() *token.FileSet {
+	return p.fset
+}
+
+// Files returns the syntax trees of the package files.
+func (p *Package) Files() []*ast.File {
+	return p.files
+}
+
+// TypeOf returns the type of an expression or nil if it was not recorded.
+func (p *Package) TypeOf(e ast.Expr) types.Type {
+	return p.info.TypeOf(e)
+}
+
+// ObjectOf returns the object an identifier defines or uses.
+func (p *Package) ObjectOf(id *ast.Ident) types.Object {
+	return p.info.ObjectOf(id)
 }
 
 func (p *Package) Name() string {
@@ -27,6 +72,9 @@ func (p *Package) Name() string {
 func (p *Package) Path() string {
 	return p.pkg.Path()
 }
+
+// Qualifier returns a qualifier relative to the package.
+// Use File.Qualifier to refer to packages by their import alias in generated files.
 func (p *Package) Qualifier() types.Qualifier {
 	if p.qual == nil {
 		p.qual = types.RelativeTo(p.pkg)
@@ -34,6 +82,12 @@ func (p *Package) Qualifier() types.Qualifier {
 	return p.qual
 }
 
+// NewFile creates a file for code generated into the package.
+// Each file has its own import set so aliases are resolved per file.
+func (p *Package) NewFile() *File {
+	return NewFile(p.Name(), p.pkg)
+}
+
 func (p *Package) Code(format string, args ...interface{}) (c Code) {
 	return c.QPrintf(p.Qualifier(), format, args...)
 }
@@ -54,21 +108,83 @@ func IgnoreTestFiles(f os.FileInfo) bool {
 	return !strings.HasSuffix(f.Name(), "_test.go")
 }
 
+// CheckMode controls the amount of type-checking done by a Parser.
+type CheckMode uint
+
+const (
+	// CheckFuncBodies type-checks function bodies.
+	CheckFuncBodies CheckMode = 1 << iota
+	// CheckAllErrors collects all type errors instead of failing on the first one.
+	// The partially checked package is returned and the errors are
+	// available from Package.Errors.
+	CheckAllErrors
+)
+
+// TypeErrors is a list of type errors collected while checking a package.
+type TypeErrors []types.Error
+
+func (errs TypeErrors) Error() string {
+	switch len(errs) {
+	case 0:
+		return "No type errors"
+	case 1:
+		return errs[0].Error()
+	default:
+		return fmt.Sprintf("%s (and %d more errors)", errs[0].Error(), len(errs)-1)
+	}
+}
+
 type Parser struct {
-	fset  *token.FileSet
-	mode  parser.Mode
-	files map[string][]*ast.File
+	fset     *token.FileSet
+	mode     parser.Mode
+	check    CheckMode
+	files    map[string][]*ast.File
+	ctxt     *build.Context
+	importer types.Importer
 }
 
 func NewParser(mode parser.Mode) *Parser {
+	fset := token.NewFileSet()
 	p := Parser{
-		fset:  token.NewFileSet(),
-		mode:  mode,
-		files: make(map[string][]*ast.File),
+		fset:     fset,
+		mode:     mode,
+		files:    make(map[string][]*ast.File),
+		ctxt:     &build.Default,
+		importer: NewImporter(fset, nil),
 	}
 	return &p
 }
 
+// SetBuildContext sets the build context used to select files by build
+// constraints and GOOS/GOARCH suffixes.
+// It also resets the importer to resolve imports using the same context.
+func (p *Parser) SetBuildContext(ctxt *build.Context) {
+	if ctxt == nil {
+		ctxt = &build.Default
+	}
+	p.ctxt = ctxt
+	p.importer = NewImporter(p.fset, ctxt)
+}
+
+// SetCheckMode sets the amount of type-checking done for parsed packages.
+func (p *Parser) SetCheckMode(mode CheckMode) {
+	p.check = mode
+}
+
+// BuildContext returns the build context used by the parser.
+func (p *Parser) BuildContext() *build.Context {
+	return p.ctxt
+}
+
+// SetImporter sets the importer used to type-check packages.
+// By default packages are imported from source with module support.
+func (p *Parser) SetImporter(imp types.Importer) {
+	if imp == nil {
+		imp = NewImporter(p.fset, p.ctxt)
+	}
+	p.importer = imp
+}
+
 func (p *Parser) ParseFile(filename string, src interface{}) (string, error) {
 	f, err := parser.ParseFile(p.fset, filename, src, p.mode)
 	if err != nil {
@@ -79,11 +195,27 @@ func (p *Parser) ParseFile(filename string, src interface{}) (string, error) {
 	return pkgName, nil
 }
 
+// ParseDir parses the files in a directory that match the parser's build
+// context and filter.
 func (p *Parser) ParseDir(path string, filter func(os.FileInfo) bool) error {
-	packages, err := parser.ParseDir(p.fset, path, filter, p.mode)
+	var matchErr error
+	match := func(f os.FileInfo) bool {
+		if filter != nil && !filter(f) {
+			return false
+		}
+		ok, err := p.ctxt.MatchFile(path, f.Name())
+		if err != nil && matchErr == nil {
+			matchErr = err
+		}
+		return ok
+	}
+	packages, err := parser.ParseDir(p.fset, path, match, p.mode)
 	if err != nil {
 		return err
 	}
+	if matchErr != nil {
+		return matchErr
+	}
 	for name, pkg := range packages {
 		for _, f := range pkg.Files {
 			p.files[name] = append(p.files[name], f)
@@ -106,27 +238,145 @@ func (p *Parser) Package(name, path string, filter func(*ast.File) bool) (*Packa
 		}
 		files = filtered
 	}
+	return p.checkFiles(p.importer, path, files)
+}
+
+// TestPackages type-checks the test variants of a parsed package.
+//
+// The test package includes the in-package _test.go files and the xtest
+// package is the external name_test package importing the test package.
+// If there are no external test files xtest is nil.
+// Test files must not be excluded by the filter passed to ParseDir.
+func (p *Parser) TestPackages(name, path string) (test, xtest *Package, err error) {
+	files, ok := p.files[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("Package %s not parsed", name)
+	}
+	if test, err = p.checkFiles(p.importer, path, files); err != nil {
+		return nil, nil, err
+	}
+	xfiles, ok := p.files[name+"_test"]
+	if !ok {
+		return test, nil, nil
+	}
+	if xtest, err = p.checkFiles(p.testImporter(test.pkg), path+"_test", xfiles); err != nil {
+		return nil, nil, err
+	}
+	return test, xtest, nil
+}
+
+// testImporter resolves imports of the package under test to its test variant.
+//
+// Like `go test` it re-checks the imported packages that depend on the
+// package under test against the test variant.
+type testImporter struct {
+	*Importer
+	base    types.Importer
+	test    *types.Package
+	depends map[string]bool
+}
+
+func (p *Parser) testImporter(test *types.Package) *testImporter {
+	return &testImporter{
+		Importer: NewImporter(p.fset, p.ctxt),
+		base:     p.importer,
+		test:     test,
+		depends:  make(map[string]bool),
+	}
+}
+
+func (imp *testImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, ".", 0)
+}
+
+func (imp *testImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if path == imp.test.Path() {
+		return imp.test, nil
+	}
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	bp, err := imp.resolve(path, dir)
+	if err != nil {
+		return nil, err
+	}
+	dependsOnTest, err := imp.dependsOnTest(bp)
+	if err != nil {
+		return nil, err
+	}
+	if dependsOnTest {
+		return imp.check(bp, imp)
+	}
+	if from, ok := imp.base.(types.ImporterFrom); ok {
+		return from.ImportFrom(path, dir, mode)
+	}
+	return imp.base.Import(path)
+}
+
+// dependsOnTest checks if a package imports the package under test.
+func (imp *testImporter) dependsOnTest(bp *build.Package) (bool, error) {
+	if bp.Goroot {
+		return false, nil
+	}
+	if depends, ok := imp.depends[bp.Dir]; ok {
+		return depends, nil
+	}
+	imp.depends[bp.Dir] = false
+	for _, path := range bp.Imports {
+		if path == imp.test.Path() {
+			imp.depends[bp.Dir] = true
+			return true, nil
+		}
+		if path == "C" || path == "unsafe" {
+			continue
+		}
+		dep, err := imp.resolve(path, bp.Dir)
+		if err != nil {
+			return false, err
+		}
+		if depends, err := imp.dependsOnTest(dep); err != nil || depends {
+			imp.depends[bp.Dir] = depends
+			return depends, err
+		}
+	}
+	return false, nil
+}
+
+func (p *Parser) checkFiles(imp types.Importer, path string, files []*ast.File) (*Package, error) {
 	config := types.Config{
-		IgnoreFuncBodies: true,
+		IgnoreFuncBodies: p.check&CheckFuncBodies == 0,
 		FakeImportC:      true,
-		Importer:         importer.Default(),
+		Importer:         imp,
 	}
 	info := types.Info{
-		Types: make(map[ast.Expr]types.TypeAndValue),
-		Defs:  make(map[*ast.Ident]types.Object),
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	var errors TypeErrors
+	if p.check&CheckAllErrors != 0 {
+		config.Error = func(err error) {
+			if err, ok := err.(types.Error); ok {
+				errors = append(errors, err)
+			}
+		}
 	}
 	pkg, err := config.Check(path, p.fset, files, &info)
-	if err != nil {
+	if err != nil && config.Error == nil {
 		return nil, err
 	}
 	return &Package{
-		pkg:   pkg,
-		fset:  p.fset,
-		info:  info,
-		files: files,
-		qual:  types.RelativeTo(pkg),
+		pkg:    pkg,
+		fset:   p.fset,
+		info:   info,
+		files:  files,
+		qual:   types.RelativeTo(pkg),
+		errors: errors,
 	}, nil
-
 }
 
 func (p *Package) NamedTypes() map[string]*types.Named {
@@ -198,6 +448,25 @@ func (p *Package) DefinedVars(filter TypeFilter) (vars []Var) {
 	return
 }
 
+// DefinedConsts returns the package level constants in declaration order.
+func (p *Package) DefinedConsts(filter TypeFilter) (consts []*types.Const) {
+	if p == nil || p.info.Defs == nil {
+		return nil
+	}
+	for _, f := range p.files {
+		ForEachConstSpec(f, func(spec *ast.ValueSpec) {
+			for _, id := range spec.Names {
+				if c, ok := p.info.Defs[id].(*types.Const); ok {
+					if filter == nil || filter(c.Type()) {
+						consts = append(consts, c)
+					}
+				}
+			}
+		})
+	}
+	return
+}
+
 func (p *Package) TypeString(t types.Type) string {
 	return types.TypeString(t, p.qual)
 }
@@ -218,12 +487,21 @@ func (p *Package) LookupType(name string) (T *types.Named) {
 	return
 }
 
+// Instantiate instantiates a generic named type of the package with type arguments.
+func (p *Package) Instantiate(name string, args ...types.Type) (types.Type, error) {
+	T := p.LookupType(name)
+	if T == nil {
+		return nil, fmt.Errorf("Type %s not found in package %s", name, p.Path())
+	}
+	return Instantiate(T, args...)
+}
+
 func (p *Package) Fprint(w io.Writer, node interface{}) error {
 	return printer.Fprint(w, p.fset, node)
 }
 
 func MustImport(path string) *types.Package {
-	imp := importer.Default()
+	imp := NewImporter(nil, nil)
 	pkg, err := imp.Import(path)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,179 @@
+package meta_test
+
+import (
+	"go/build"
+	"go/types"
+	"os"
+	"testing"
+
+	"github.com/alxarch/meta"
+)
+
+func TestParserModule(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/mod/b", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("b", "example.com/mod/b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.FindImport("example.com/mod/a") == nil {
+		t.Errorf("Module import not resolved")
+	}
+	if pkg.LookupType("B") == nil {
+		t.Errorf("Type B not found")
+	}
+}
+
+func TestLoadPackage(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("testdata/mod"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	packages, err := meta.LoadPackage("./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 5 {
+		t.Fatalf("Invalid number of packages %d", len(packages))
+	}
+	if pkg := packages[1]; pkg.Name() != "b" || pkg.Path() != "example.com/mod/b" {
+		t.Errorf("Invalid package %s %s", pkg.Name(), pkg.Path())
+	}
+	if a, b := packages[0], packages[1]; b.FindImport("example.com/mod/a") != a.Types() {
+		t.Errorf("Loaded packages do not share imported packages")
+	}
+
+	// e imports c only through d which is not loaded
+	packages, err = meta.LoadPackage("./e", "./c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, c := packages[0], packages[1]
+	v := e.Types().Scope().Lookup("V").Type().(*types.Signature)
+	if !types.Identical(v.Params().At(0).Type(), c.LookupType("C")) {
+		t.Errorf("Loaded packages do not share indirectly imported packages")
+	}
+
+	packages, err = meta.NewParser(0).LoadTestPackages("./c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 2 {
+		t.Fatalf("Invalid number of test packages %d", len(packages))
+	}
+	if pkg := packages[1]; pkg.Name() != "c_test" {
+		t.Errorf("Invalid external test package %s", pkg.Name())
+	}
+}
+
+func TestParserBuildContext(t *testing.T) {
+	ctxt := build.Default
+	ctxt.GOOS = "windows"
+	ctxt.BuildTags = []string{"foo"}
+	p := meta.NewParser(0)
+	p.SetBuildContext(&ctxt)
+	if err := p.ParseDir("testdata/build", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("build", "example.com/build", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"Tag": "Foo",
+		"OS":  "Windows",
+	} {
+		s, ok := meta.Struct(pkg.LookupType(name))
+		if !ok {
+			t.Errorf("Type %s not found", name)
+			continue
+		}
+		if field := s.Field(0).Name(); field != want {
+			t.Errorf("Invalid %s field %s", name, field)
+		}
+	}
+}
+
+func TestParserTestPackages(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/mod/c", nil); err != nil {
+		t.Fatal(err)
+	}
+	test, xtest, err := p.TestPackages("c", "example.com/mod/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.LookupType("mockC") == nil {
+		t.Errorf("Test type not found")
+	}
+	if xtest == nil {
+		t.Fatal("External test package not found")
+	}
+	if xtest.Path() != "example.com/mod/c_test" {
+		t.Errorf("Invalid external test package path %s", xtest.Path())
+	}
+	if xtest.FindImport("example.com/mod/c") != test.Types() {
+		t.Errorf("External test package does not import the test package")
+	}
+	if d := xtest.FindImport("example.com/mod/d"); d == nil || d.Imports()[0] != test.Types() {
+		t.Errorf("Dependency of the external test package does not import the test package")
+	}
+}
+
+func TestParserCheckFuncBodies(t *testing.T) {
+	p := meta.NewParser(0)
+	p.SetCheckMode(meta.CheckFuncBodies)
+	if err := p.ParseDir("testdata/body", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("body", "example.com/body", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uses := 0
+	for id, obj := range pkg.Info().Uses {
+		if id.Name == "n" {
+			if _, ok := obj.(*types.Var); !ok {
+				t.Errorf("Invalid object %s", obj)
+			}
+			uses++
+		}
+	}
+	if uses != 2 {
+		t.Errorf("Invalid uses of n in function body %d", uses)
+	}
+	if len(pkg.Info().Selections) == 0 {
+		t.Errorf("No selections recorded")
+	}
+}
+
+func TestParserCheckAllErrors(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/broken", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Package("broken", "example.com/broken", nil); err == nil {
+		t.Fatal("Expected type error")
+	}
+	p.SetCheckMode(meta.CheckAllErrors)
+	pkg, err := p.Package("broken", "example.com/broken", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := pkg.Errors(); len(errs) != 2 {
+		t.Errorf("Invalid number of errors %d", len(errs))
+	}
+	if pkg.Err() == nil {
+		t.Errorf("Expected package error")
+	}
+	if pkg.LookupType("T") == nil {
+		t.Errorf("Partially checked type not found")
+	}
+}
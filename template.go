@@ -0,0 +1,89 @@
+package meta
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"text/template"
+)
+
+// TemplateFuncs returns the type-aware functions available to templates.
+//
+//	typeString T   prints a type using the qualifier and records its imports
+//	fieldsOf T     returns the fields of a struct type including embedded fields
+//	tag KEY FIELD  parses the tag of a field for a key
+//	hasTag KEY FIELD checks if a field has a tag for a key
+//	zeroValue T    prints the zero value expression of a type
+//
+// Imports are recorded in c if it is not nil.
+// Templates must be parsed with these functions before being executed
+// with ExecuteTemplate.
+func TemplateFuncs(q types.Qualifier, c *Code) template.FuncMap {
+	if c == nil {
+		c = &Code{}
+	}
+	return template.FuncMap{
+		"typeString": func(t types.Type) string {
+			*c = c.Import(t)
+			return types.TypeString(t, q)
+		},
+		"fieldsOf": fieldsOf,
+		"tag": func(key string, f Field) Tag {
+			tag, ok := ParseTag(f.Tag, key)
+			tag.Key = key
+			tag.Missing = !ok
+			return tag
+		},
+		"hasTag": func(key string, f Field) bool {
+			return HasTag(f.Tag, key)
+		},
+		"zeroValue": func(t types.Type) (string, error) {
+			z := ZeroValue(t, q)
+			*c = c.Import(z)
+			return z.String(), z.Err()
+		},
+	}
+}
+
+// ExecuteTemplate executes a template and returns the generated code
+// with the imports of all types printed by the template functions.
+func ExecuteTemplate(t *template.Template, q types.Qualifier, data interface{}) (c Code) {
+	t, err := t.Clone()
+	if err != nil {
+		return c.Error(err)
+	}
+	t = t.Funcs(TemplateFuncs(q, &c))
+	if err := t.Execute(&c, data); err != nil {
+		return c.Error(err)
+	}
+	return c
+}
+
+// ExecuteTemplate executes a template using the package qualifier.
+func (p *Package) ExecuteTemplate(t *template.Template, data interface{}) Code {
+	return ExecuteTemplate(t, p.Qualifier(), data)
+}
+
+// fieldsOf returns the visible fields of a struct in declaration order.
+func fieldsOf(t types.Type) ([]Field, error) {
+	s, ok := Struct(t)
+	if !ok {
+		return nil, fmt.Errorf("Type %s is not a struct", t)
+	}
+	fields := make([]Field, 0, s.NumFields())
+	for _, f := range NewFields(s, true) {
+		if len(f) > 0 {
+			fields = append(fields, f[0])
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].Path, fields[j].Path
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k].Index != b[k].Index {
+				return a[k].Index < b[k].Index
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields, nil
+}
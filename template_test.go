@@ -0,0 +1,47 @@
+package meta_test
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/alxarch/meta"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	p := meta.NewParser(0)
+	if err := p.ParseDir("testdata/template", nil); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := p.Package("template", "example.com/template", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := template.Must(template.New("reset").Funcs(meta.TemplateFuncs(nil, nil)).Parse(
+		`func (e *Event) Reset() {
+{{- range fieldsOf (.LookupType "Event") }}
+{{- if hasTag "json" . }}
+	e.{{ .Name }} = {{ zeroValue .Type }} // {{ (tag "json" .).Name }} {{ typeString .Type }}
+{{- else }}
+	e.{{ .Name }} = {{ zeroValue .Type }}
+{{- end }}
+{{- end }}
+}
+`))
+	c := pkg.ExecuteTemplate(tmpl, pkg)
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := `func (e *Event) Reset() {
+	e.ID = 0 // id int
+	e.Name = "" // name string
+	e.At = time.Time{} // at time.Time
+	e.Tags = nil
+}
+`
+	if c.String() != expect {
+		t.Errorf("Invalid template output:\n%s", c)
+	}
+	if len(c.Imports) != 1 || c.Imports[0].Path() != "time" {
+		t.Errorf("Invalid imports %v", c.Imports)
+	}
+}
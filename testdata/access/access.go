@@ -0,0 +1,31 @@
+package access
+
+type inner struct {
+	Foo int
+	bar int
+}
+
+type Exported struct {
+	Baz int
+}
+
+type ptrInner struct {
+	Qux int
+}
+
+type other struct {
+	Foo string
+}
+
+type T struct {
+	inner
+	*Exported
+	*ptrInner
+	Name   string
+	secret string
+}
+
+type Ambiguous struct {
+	inner
+	other
+}
@@ -0,0 +1,11 @@
+package body
+
+type T struct {
+	N int
+}
+
+func (t *T) Inc() int {
+	n := t.N + 1
+	t.N = n
+	return n
+}
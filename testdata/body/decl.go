@@ -0,0 +1,9 @@
+package body
+
+// Reset sets the counter to zero.
+func (t *T) Reset() {
+	prev := t.N
+
+	t.N = 0
+	_ = prev
+}
@@ -0,0 +1,7 @@
+package broken
+
+type T struct {
+	Gen Generated
+}
+
+var _ = Missing()
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package build
+
+type OS struct {
+	Other int
+}
@@ -0,0 +1,5 @@
+package build
+
+type OS struct {
+	Windows int
+}
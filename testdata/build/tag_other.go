@@ -0,0 +1,7 @@
+//go:build !foo
+
+package build
+
+type Tag struct {
+	Other int
+}
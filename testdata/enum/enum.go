@@ -0,0 +1,19 @@
+package enum
+
+type Weekday int
+
+const (
+	Sunday Weekday = iota
+	Monday
+	Tuesday
+	FirstDay = Sunday
+)
+
+type Color string
+
+const (
+	Red   Color = "red"
+	Green Color = "green"
+)
+
+const Answer = 42
@@ -0,0 +1,72 @@
+// Command roundtrip checks that generated enum methods decode
+// what they encode and reject values that are not in the enum.
+package main
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"example.com/enum"
+)
+
+type textCodec interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+func main() {
+	failed := false
+	check := func(v, decoded textCodec, valid bool) {
+		text, err := v.MarshalText()
+		if !valid {
+			if err == nil {
+				fmt.Printf("MarshalText %v: expected error\n", v)
+				failed = true
+			}
+			if _, err := json.Marshal(v); err == nil {
+				fmt.Printf("MarshalJSON %v: expected error\n", v)
+				failed = true
+			}
+			return
+		}
+		if err != nil {
+			fmt.Printf("MarshalText %v: %s\n", v, err)
+			failed = true
+		} else if err := decoded.UnmarshalText(text); err != nil {
+			fmt.Printf("UnmarshalText %s: %s\n", text, err)
+			failed = true
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			fmt.Printf("MarshalJSON %v: %s\n", v, err)
+			failed = true
+		} else if err := json.Unmarshal(data, decoded); err != nil {
+			fmt.Printf("UnmarshalJSON %s: %s\n", data, err)
+			failed = true
+		}
+	}
+	for _, v := range enum.WeekdayValues() {
+		var decoded enum.Weekday
+		check(&v, &decoded, true)
+		if decoded != v {
+			fmt.Printf("Decoded %v != %v\n", decoded, v)
+			failed = true
+		}
+	}
+	for _, v := range enum.ColorValues() {
+		var decoded enum.Color
+		check(&v, &decoded, true)
+		if decoded != v {
+			fmt.Printf("Decoded %v != %v\n", decoded, v)
+			failed = true
+		}
+	}
+	weekday, color := enum.Weekday(5), enum.Color("blue")
+	check(&weekday, new(enum.Weekday), false)
+	check(&color, new(enum.Color), false)
+	if failed {
+		os.Exit(1)
+	}
+}
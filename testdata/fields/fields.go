@@ -0,0 +1,62 @@
+package fields
+
+type A struct {
+	Name string
+	X    int `json:"x"`
+}
+
+type B struct {
+	Name string
+	Y    int `json:"x"`
+}
+
+type C struct {
+	Name string `json:"Name"`
+}
+
+type Inner struct {
+	A
+	Deep int
+}
+
+type Conflict struct {
+	A
+	B
+}
+
+type Tagged struct {
+	A
+	C
+}
+
+type Shallow struct {
+	A
+	Name string
+}
+
+type Nested struct {
+	Inner
+	*A
+	Other int `json:"-"`
+}
+
+type Self struct {
+	*Self
+	ID int
+}
+
+type Yaml struct {
+	A `yaml:"a"`
+	Z int `yaml:"-"`
+	W int `yaml:"w,omitempty"`
+}
+
+type Loop struct {
+	*Chain
+	L int
+}
+
+type Chain struct {
+	*Loop
+	C int
+}
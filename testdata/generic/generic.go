@@ -0,0 +1,27 @@
+package generic
+
+import "time"
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+type Integer interface {
+	Number
+	~int | ~int64 | ~string
+}
+
+type Slice[T any] interface {
+	~[]T
+}
+
+type List[T Number] struct {
+	Items []T
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Events = Pair[string, time.Time]
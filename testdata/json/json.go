@@ -0,0 +1,63 @@
+package json
+
+import "time"
+
+type Base struct {
+	ID      int64 `json:"id"`
+	Created time.Time
+}
+
+type Meta struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type Extra struct {
+	Note string
+}
+
+type hidden struct {
+	Visible bool
+}
+
+type User struct {
+	Base
+	*Meta
+	hidden
+	Extra    `json:"extra"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email,omitempty"`
+	Age      uint8   `json:",string"`
+	Score    float64 `json:"score,omitempty,string"`
+	Limit    *int    `json:"limit,string"`
+	Day      Day     `json:"day,string"`
+	Next     *Day    `json:"next,omitempty,string"`
+	Password string  `json:"-"`
+	Dash     int     `json:"-,"`
+	Parent   *User   `json:"parent,omitempty"`
+	secret   string
+}
+
+type Day int
+
+var days = []string{"Mon", "Tue"}
+
+func (d Day) MarshalJSON() ([]byte, error) {
+	if d < 0 || int(d) >= len(days) {
+		return []byte(`"?"`), nil
+	}
+	return []byte(`"` + days[d] + `"`), nil
+}
+
+func (d *Day) UnmarshalJSON(data []byte) error {
+	*d = -1
+	for i, s := range days {
+		if string(data) == `"`+s+`"` {
+			*d = Day(i)
+		}
+	}
+	return nil
+}
+
+type Empty struct {
+	secret int
+}
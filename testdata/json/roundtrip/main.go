@@ -0,0 +1,80 @@
+// Command roundtrip compares the generated JSON methods of User
+// with encoding/json on a copy of the type without methods.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	user "example.com/json"
+)
+
+// plainUser has the fields of User but not its methods.
+type plainUser user.User
+
+func main() {
+	failed := false
+	for _, v := range []user.User{{}, sample()} {
+		got, err := json.Marshal(v)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		want, _ := json.Marshal(plainUser(v))
+		if string(got) != string(want) {
+			fmt.Printf("Marshal %s != %s\n", got, want)
+			failed = true
+		}
+	}
+	for _, data := range []string{
+		`{}`,
+		`{"Age":null}`,
+		`{"limit":null}`,
+		`{"Age":"7","score":"2.5","limit":"3"}`,
+		`{"ID":2,"NAME":"baz","Tags":["x"],"Visible":true,"-":4}`,
+		`{"extra":{"Note":"n"},"parent":{"name":"p","limit":"1"}}`,
+		`{"Created":"2020-01-02T03:04:05Z","email":"e"}`,
+		`{"Age":7}`,
+		`{"day":"Tue","next":"Mon"}`,
+		`{"day":"\"Tue\"","next":null}`,
+		`{"limit":"x"}`,
+	} {
+		got, want := sample(), plainUser(sample())
+		errGot := json.Unmarshal([]byte(data), &got)
+		errWant := json.Unmarshal([]byte(data), &want)
+		if (errGot != nil) != (errWant != nil) {
+			fmt.Printf("Unmarshal %s error %v != %v\n", data, errGot, errWant)
+			failed = true
+		} else if errWant == nil && !reflect.DeepEqual(plainUser(got), want) {
+			fmt.Printf("Unmarshal %s %+v != %+v\n", data, got, want)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// sample returns a new User with all fields set.
+func sample() user.User {
+	limit, next := 3, user.Day(0)
+	return user.User{
+		Base:  user.Base{ID: 1, Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Meta:  &user.Meta{Tags: []string{"a", "b"}},
+		Extra: user.Extra{Note: "<note>"},
+		Name:  "foo",
+		Email: "foo@example.com",
+		Age:   42,
+		Score: 1.5,
+		Limit: &limit,
+		Day:   1,
+		Next:  &next,
+		Dash:  -1,
+		Parent: &user.User{
+			Name: "bar",
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package methods
+
+type Namer interface {
+	Name() string
+}
+
+type Resetter interface {
+	Reset()
+}
+
+type NameResetter interface {
+	Namer
+	Resetter
+}
+
+type Value struct{}
+
+func (Value) Name() string { return "value" }
+
+type Pointer struct {
+	name string
+}
+
+func (p *Pointer) Name() string { return p.name }
+
+func (p *Pointer) Reset() { p.name = "" }
+
+type None int
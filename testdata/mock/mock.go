@@ -0,0 +1,15 @@
+package mock
+
+import (
+	"context"
+	"io"
+)
+
+type Key string
+
+type Store interface {
+	io.Closer
+	Get(ctx context.Context, key Key) (string, error)
+	Set(context.Context, Key, string) error
+	Delete(ctx context.Context, keys ...Key) (n int, err error)
+}
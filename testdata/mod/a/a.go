@@ -0,0 +1,5 @@
+package a
+
+type A struct {
+	Name string
+}
@@ -0,0 +1,8 @@
+package b
+
+import "example.com/mod/a"
+
+type B struct {
+	a.A
+	Count int
+}
@@ -0,0 +1,3 @@
+package c
+
+type C struct{}
@@ -0,0 +1,5 @@
+package c
+
+type mockC struct {
+	C
+}
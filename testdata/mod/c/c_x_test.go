@@ -0,0 +1,12 @@
+package c_test
+
+import (
+	"example.com/mod/c"
+	"example.com/mod/d"
+)
+
+type Mock = c.Mock
+
+var _ = c.NewMock
+
+var _ func(c.C) = d.F
@@ -0,0 +1,7 @@
+package c
+
+type Mock = mockC
+
+func NewMock() *Mock {
+	return &mockC{}
+}
@@ -0,0 +1,5 @@
+package e
+
+import "example.com/mod/d"
+
+var V = d.F
@@ -0,0 +1,14 @@
+package template
+
+import "time"
+
+type Base struct {
+	ID int `json:"id"`
+}
+
+type Event struct {
+	Base
+	Name string    `json:"name,omitempty"`
+	At   time.Time `json:"at"`
+	Tags []string
+}
@@ -5,12 +5,31 @@ import (
 	"go/types"
 )
 
+// TypeImports appends the packages referenced by types and objects to imports.
+// Constraints of type parameters are only included from type parameter lists
+// where they are declared.
 func TypeImports(imports []*types.Package, t ...interface{}) []*types.Package {
 	for _, t := range t {
 		switch t := t.(type) {
 		case *types.Named:
 			if t != nil {
-				imports = TypeImports(imports, t.Obj())
+				imports = TypeImports(imports, t.Obj(), t.TypeArgs())
+			}
+		case *types.Alias:
+			if t != nil {
+				imports = TypeImports(imports, t.Obj(), t.TypeArgs())
+			}
+		case *types.TypeList:
+			if t != nil {
+				for i := 0; i < t.Len(); i++ {
+					imports = TypeImports(imports, t.At(i))
+				}
+			}
+		case *types.TypeParamList:
+			if t != nil {
+				for i := 0; i < t.Len(); i++ {
+					imports = TypeImports(imports, t.At(i).Constraint())
+				}
 			}
 		case *types.Scope:
 			if t != nil {
@@ -24,20 +43,45 @@ func TypeImports(imports []*types.Package, t ...interface{}) []*types.Package {
 			}
 		case *types.Map:
 			if t != nil {
-				imports = TypeImports(imports, t.Elem())
+				imports = TypeImports(imports, t.Key())
 				imports = TypeImports(imports, t.Elem())
 			}
 		case *types.Slice:
 			if t != nil {
 				imports = TypeImports(imports, t.Elem())
 			}
+		case *types.Array:
+			if t != nil {
+				imports = TypeImports(imports, t.Elem())
+			}
 		case *types.Chan:
 			if t != nil {
 				imports = TypeImports(imports, t.Elem())
 			}
+		case *types.Struct:
+			if t != nil {
+				for i := 0; i < t.NumFields(); i++ {
+					imports = TypeImports(imports, t.Field(i))
+				}
+			}
+		case *types.Interface:
+			if t != nil {
+				for i := 0; i < t.NumExplicitMethods(); i++ {
+					imports = TypeImports(imports, t.ExplicitMethod(i).Type())
+				}
+				for i := 0; i < t.NumEmbeddeds(); i++ {
+					imports = TypeImports(imports, t.EmbeddedType(i))
+				}
+			}
+		case *types.Union:
+			if t != nil {
+				for i := 0; i < t.Len(); i++ {
+					imports = TypeImports(imports, t.Term(i).Type())
+				}
+			}
 		case *types.Signature:
 			if t != nil {
-				imports = TypeImports(imports, t.Recv(), t.Params(), t.Results())
+				imports = TypeImports(imports, t.TypeParams(), t.Params(), t.Results())
 			}
 		case *types.Var:
 			if t != nil {
@@ -49,8 +93,12 @@ func TypeImports(imports []*types.Package, t ...interface{}) []*types.Package {
 					imports = TypeImports(imports, t.At(i))
 				}
 			}
+		case *types.Basic:
+			if t != nil && t.Kind() == types.UnsafePointer {
+				imports = TypeImports(imports, types.Unsafe)
+			}
 		case *types.Package:
-			if t != nil {
+			if t != nil && !hasImport(imports, t.Path()) {
 				imports = append(imports, t)
 			}
 		case *types.TypeName:
@@ -64,6 +112,15 @@ func TypeImports(imports []*types.Package, t ...interface{}) []*types.Package {
 
 }
 
+func hasImport(imports []*types.Package, path string) bool {
+	for _, pkg := range imports {
+		if pkg.Path() == path {
+			return true
+		}
+	}
+	return false
+}
+
 func Embedded(field *types.Var) (*types.Struct, bool) {
 	if field != nil && field.IsField() && field.Anonymous() {
 		return Struct(field.Type())
@@ -80,7 +137,7 @@ func Base(t types.Type) string {
 
 func String(t types.Type) (*types.Basic, bool) {
 	if t != nil {
-		if s, ok := t.Underlying().(*types.Basic); ok && s.Kind() == types.String {
+		if s, ok := CoreType(t).(*types.Basic); ok && s.Kind() == types.String {
 			return s, true
 		}
 	}
@@ -89,7 +146,7 @@ func String(t types.Type) (*types.Basic, bool) {
 }
 func Struct(t types.Type) (*types.Struct, bool) {
 	if t != nil {
-		if s, ok := t.Underlying().(*types.Struct); ok {
+		if s, ok := CoreType(t).(*types.Struct); ok {
 			return s, true
 		}
 	}
@@ -98,7 +155,7 @@ func Struct(t types.Type) (*types.Struct, bool) {
 
 func Pointer(t types.Type) (*types.Pointer, bool) {
 	if t != nil {
-		if s, ok := t.Underlying().(*types.Pointer); ok {
+		if s, ok := CoreType(t).(*types.Pointer); ok {
 			return s, true
 		}
 	}
@@ -107,17 +164,28 @@ func Pointer(t types.Type) (*types.Pointer, bool) {
 
 func Slice(t types.Type) (*types.Slice, bool) {
 	if t != nil {
-		if s, ok := t.Underlying().(*types.Slice); ok {
+		if s, ok := CoreType(t).(*types.Slice); ok {
 			return s, true
 		}
 	}
 	return nil, false
 }
 
+// Deref returns the element type of a pointer type.
+func Deref(t types.Type) types.Type {
+	if p, ok := t.(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return t
+}
+
 func Sized(t types.Type) bool {
 	if t == nil {
 		return false
 	}
+	if p, ok := TypeParam(t); ok {
+		return allTerms(p, Sized)
+	}
 	switch t := t.Underlying().(type) {
 	case *types.Pointer:
 		return Sized(t.Elem())
@@ -136,6 +204,9 @@ func Nilable(t types.Type) bool {
 	if t == nil {
 		return false
 	}
+	if p, ok := TypeParam(t); ok {
+		return allTerms(p, Nilable)
+	}
 	switch t.Underlying().(type) {
 	case *types.Pointer:
 		return true
@@ -152,7 +223,7 @@ func Nilable(t types.Type) bool {
 
 func Basic(t types.Type) (*types.Basic, bool) {
 	if t != nil {
-		if t, ok := t.Underlying().(*types.Basic); ok {
+		if t, ok := CoreType(t).(*types.Basic); ok {
 			return t, ok
 		}
 	}
@@ -197,6 +268,13 @@ func IsStruct(t types.Type) (ok bool) {
 	return
 }
 
+func IsInterface(t types.Type) (ok bool) {
+	if t != nil {
+		_, ok = t.Underlying().(*types.Interface)
+	}
+	return
+}
+
 func Vars(pkg *types.Package, typ ...types.Type) (v []*types.Var) {
 	v = make([]*types.Var, len(typ))
 	for i, typ := range typ {
@@ -212,11 +290,9 @@ func ErrorInterface() *types.Interface {
 }
 
 func MakeInterface(name string, params []types.Type, results []types.Type, v bool) *types.Interface {
-	vparams := Vars(nil, params...)
-	vresults := Vars(nil, results...)
-	sig := types.NewSignature(nil, types.NewTuple(vparams...), types.NewTuple(vresults...), v)
-	fn := types.NewFunc(token.NoPos, nil, name, sig)
-	iface := types.NewInterface([]*types.Func{fn}, []*types.Named{})
-	return iface.Complete()
-
+	iface, err := NewInterfaceBuilder(nil).Method(name, Vars(nil, params...), Vars(nil, results...), v).Interface()
+	if err != nil {
+		panic(err)
+	}
+	return iface
 }